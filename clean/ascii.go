@@ -2,7 +2,7 @@
 // ---------
 // Converts Unicode filenames to ASCII-safe equivalents using NFKD normalization.
 
-package main
+package clean
 
 import (
 	"unicode"
@@ -10,7 +10,10 @@ import (
 	"golang.org/x/text/unicode/norm"
 )
 
-func cleanASCII(s string) string {
+// CleanASCII folds s to printable ASCII: it decomposes s with NFKD, drops
+// combining marks, maps common ligatures and punctuation to ASCII
+// equivalents, removes control characters, and replaces anything else with '_'.
+func CleanASCII(s string) string {
 	if s == "" {
 		return s
 	}
@@ -0,0 +1,74 @@
+// atomic.go
+// ----------
+// Transactional execution for Cleanfy (--atomic).
+// When any entry of an atomic run fails, the remaining entries are not
+// attempted and all completed renames are rolled back in reverse order.
+
+package clean
+
+import "path/filepath"
+
+// errAborted is reported for entries not attempted after a failure in an atomic run.
+const errAborted = "not attempted: atomic run aborted"
+
+// Failed reports whether any result contains an error.
+func Failed(results []Result) bool {
+	for _, r := range results {
+		if r.HasError() {
+			return true
+		}
+	}
+	return false
+}
+
+// rollback reverses every completed rename in results. order lists the
+// indices of the attempted results in execution order. Directories are
+// restored in reverse order, and the renames within each directory are
+// sequenced like the run itself, so swaps are undone without clobbering.
+// Reverted results get their original path back and RolledBack set.
+// The reversing renames are journaled as an undo of the run, so the
+// journal stays consistent with the disk.
+func rollback(results []Result, order []int, opts Options) {
+	var j *Journal
+	if opts.Journal != nil {
+		j = &Journal{path: opts.Journal.path, run: newRunID(), undoOf: opts.Journal.run}
+		defer j.Close()
+	}
+
+	groups := groupByDir(order, func(i int) string { return filepath.Dir(results[i].Path) })
+	for g := len(groups) - 1; g >= 0; g-- {
+		var moves []move
+		for _, i := range groups[g] {
+			if r := results[i]; r.Renamed {
+				moves = append(moves, move{dir: filepath.Dir(r.Path), from: filepath.Base(r.Path), to: r.OldName, ref: i})
+			}
+		}
+
+		stopped := make(map[int]bool)
+		for _, m := range sequenceMoves(moves, len(moves) > 0 && opts.caseInsensitive(moves[0].dir)) {
+			r := &results[m.ref]
+			if stopped[m.ref] {
+				continue
+			}
+			if err := m.run(); err != nil {
+				r.Error = "rollback failed: " + err.Error()
+				stopped[m.ref] = true
+				continue
+			}
+			from, to := m.paths()
+			if j != nil {
+				if err := j.Record(from, to); err != nil {
+					r.Error = "rolled back but not journaled: " + err.Error()
+				}
+			}
+			if r.IsDir {
+				relocate(results, from, to)
+			}
+			r.Path = to
+			if m.to == r.OldName {
+				r.Renamed = false
+				r.RolledBack = true
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// atomic_test.go
+// ---------------
+// Unit tests for transactional (--atomic) execution.
+
+package clean
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWalkAtomicPlanningError tests that nothing is renamed when planning fails.
+func TestWalkAtomicPlanningError(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt", "B File.txt", "C Fail.txt")
+
+	p := DefaultPipeline()
+	p.Append(NewStep("fail", func(ctx *Context, base, ext string) (string, string, error) {
+		if ctx.Name == "C Fail.txt" {
+			return base, ext, errors.New("boom")
+		}
+		return base, ext, nil
+	}))
+	opts := Options{Case: "lower", Execute: true, Atomic: true, Pipeline: p}
+
+	results := Walk([]string{tmpDir}, opts)
+
+	if !Failed(results) {
+		t.Fatalf("Walk() did not report failure")
+	}
+	for _, r := range results {
+		if r.Renamed || !r.HasError() {
+			t.Errorf("Walk() %q: Renamed = %v, Error = %q, want aborted", r.OldName, r.Renamed, r.Error)
+		}
+	}
+	for _, name := range []string{"A File.txt", "B File.txt", "C Fail.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("Walk() touched %q: %v", name, err)
+		}
+	}
+}
+
+// TestApplyAtomicRollback tests that a failed rename rolls back every completed rename.
+func TestApplyAtomicRollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt", "B File.txt", "C File.txt")
+
+	plan, _ := NewPlan([]string{tmpDir}, Options{Case: "lower"})
+	// Another process takes the last destination after planning
+	touch(t, tmpDir, "c_file.txt")
+
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := NewJournal(journalFile)
+	results := Apply(plan, Options{Atomic: true, Journal: j})
+	j.Close()
+
+	if !Failed(results) {
+		t.Fatalf("Apply() did not report failure")
+	}
+	for _, r := range results {
+		if r.Renamed {
+			t.Errorf("Apply() %q still renamed after rollback", r.OldName)
+		}
+		if r.OldName != "C File.txt" && !r.RolledBack {
+			t.Errorf("Apply() %q: RolledBack = false, want true", r.OldName)
+		}
+	}
+	for _, name := range []string{"A File.txt", "B File.txt", "C File.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("rollback did not restore %q: %v", name, err)
+		}
+	}
+
+	// The rolled back run is not offered for undo
+	if got := Undo(journalFile, ""); len(got) != 1 || got[0].Error != "nothing to undo" {
+		t.Errorf("Undo() after rollback = %+v, want nothing to undo", got)
+	}
+}
+
+// TestWalkAtomicSuccess tests that an atomic run without failures keeps its renames.
+func TestWalkAtomicSuccess(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt")
+
+	results := Walk([]string{tmpDir}, Options{Case: "lower", Execute: true, Atomic: true})
+	if Failed(results) || len(results) != 1 || !results[0].Renamed {
+		t.Fatalf("Walk() = %+v, want one successful rename", results)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "a_file.txt")); err != nil {
+		t.Errorf("Walk() did not rename: %v", err)
+	}
+}
+
+// TestAtomicNeverOverwrites tests that atomic runs refuse to overwrite,
+// since a rollback could not bring back the replaced file.
+func TestAtomicNeverOverwrites(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "A File.txt", "a_file.txt", "B File.txt")
+
+	plan, _ := NewPlan([]string{tmpDir}, Options{Case: "lower", OnConflict: ConflictOverwrite})
+	if results := Apply(plan, Options{Atomic: true}); !Failed(results) {
+		t.Errorf("Apply() of an overwriting plan did not fail")
+	}
+
+	// Answering overwrite to a prompt fails the atomic run too
+	ask := func(string, string) string { return ConflictOverwrite }
+	if results := Walk([]string{tmpDir}, Options{Case: "lower", OnConflict: ConflictAsk, Ask: ask, Atomic: true, Execute: true}); !Failed(results) {
+		t.Errorf("Walk() with an overwrite answer did not fail")
+	}
+	checkContent(t, tmpDir, map[string]string{"A File.txt": "A File.txt", "a_file.txt": "a_file.txt", "B File.txt": "B File.txt"})
+}
@@ -0,0 +1,163 @@
+// batch.go
+// ---------
+// Batch collision planning for Cleanfy.
+// Proposed renames are grouped per directory and all clashes among the
+// proposed names and the existing files are resolved up front. Suffixes
+// are assigned in order of original name, so identical trees always get
+// identical results regardless of walk or argument order.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// batch collects the proposed renames of a plan, grouped by directory.
+type batch struct {
+	dirs   []string                // Directories in order of first use
+	groups map[string][]*PlanEntry // Proposed renames per directory
+}
+
+// add queues a proposed rename of e in its directory.
+func (b *batch) add(e *PlanEntry) {
+	if b.groups == nil {
+		b.groups = make(map[string][]*PlanEntry)
+	}
+	dir := filepath.Dir(e.Path)
+	if _, ok := b.groups[dir]; !ok {
+		b.dirs = append(b.dirs, dir)
+	}
+	b.groups[dir] = append(b.groups[dir], e)
+}
+
+// resolve assigns a final, collision-free NewName to every queued entry
+// and returns the entries deepest directory first. Entries whose conflict
+// strategy keeps them unchanged (ConflictSkip, ConflictError) are
+// returned too; dropped reports them.
+func (b *batch) resolve(opts Options) []*PlanEntry {
+	sort.SliceStable(b.dirs, func(i, j int) bool {
+		return pathDepth(b.dirs[i]) > pathDepth(b.dirs[j])
+	})
+
+	var out []*PlanEntry
+	for _, dir := range b.dirs {
+		entries := b.groups[dir]
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].OldName < entries[j].OldName
+		})
+		resolveDir(dir, entries, opts)
+		out = append(out, entries...)
+	}
+	return out
+}
+
+// dropped reports whether the conflict strategy of e keeps it unchanged.
+func (e *PlanEntry) dropped() bool {
+	return e.Conflict == ConflictSkip || e.Conflict == ConflictError
+}
+
+// resolveDir assigns final names to the renames of one directory.
+// Names of files on disk stay occupied, except the current names of the
+// entries themselves: those are vacated by the batch, so swaps and chains
+// (a → b while b → c) keep their proposed names. Entries that stay
+// unchanged because of their conflict strategy occupy their name again,
+// so the remaining entries are resolved once more until nothing changes.
+// In case-insensitive directories, names collide when they only differ
+// in case.
+func resolveDir(dir string, entries []*PlanEntry, opts Options) {
+	fold := opts.caseInsensitive(dir)
+	onDisk := make(map[string]bool)
+	if list, err := os.ReadDir(dir); err == nil {
+		for _, e := range list {
+			onDisk[nameKey(e.Name(), fold)] = true
+		}
+	}
+	active := entries
+	for {
+		kept := assignNames(dir, active, onDisk, fold, opts)
+		if len(kept) == len(active) {
+			return
+		}
+		active = kept
+	}
+}
+
+// assignNames resolves the names of active entries against the names on
+// disk and returns the entries that are still renamed. An entry keeps its
+// proposed name when it is free and no entry sorting before it wants the
+// same name; all others are handled by their conflict strategy. Suffixes
+// are never a name that is occupied, assigned, or proposed. All maps are
+// keyed by nameKey(name, fold).
+func assignNames(dir string, active []*PlanEntry, onDisk map[string]bool, fold bool, opts Options) []*PlanEntry {
+	key := func(name string) string { return nameKey(name, fold) }
+
+	occupied := make(map[string]bool, len(onDisk))
+	for name := range onDisk {
+		occupied[name] = true
+	}
+	proposed := make(map[string]bool, len(active))
+	for _, e := range active {
+		delete(occupied, key(filepath.Base(e.Path)))
+		proposed[key(e.IntendedName)] = true
+	}
+	assigned := make(map[string]bool, len(active))
+
+	// Entries that can keep their proposed name
+	var clashing []*PlanEntry
+	for _, e := range active {
+		e.NewName, e.Conflict, e.AutoRenamed, e.Suffix = e.IntendedName, "", false, 0
+		if occupied[key(e.NewName)] || assigned[key(e.NewName)] {
+			clashing = append(clashing, e)
+			continue
+		}
+		assigned[key(e.NewName)] = true
+	}
+
+	// Conflict strategies for the rest, in order of original name
+	taken := func(fullPath string) bool {
+		name := key(filepath.Base(fullPath))
+		return occupied[name] || assigned[name] || proposed[name]
+	}
+	for _, e := range clashing {
+		if e.strategy == "" {
+			e.strategy = opts.conflictStrategy(e.Path, e.NewName)
+		}
+		e.Conflict = e.strategy
+
+		switch e.strategy {
+		case ConflictSkip, ConflictError:
+			continue
+		case ConflictOverwrite:
+			// Only files on disk are replaced, never another entry of the run
+			if !assigned[key(e.NewName)] {
+				assigned[key(e.NewName)] = true
+				continue
+			}
+			e.Conflict = ConflictSuffix
+		case ConflictHash:
+			if h, err := contentHash(e.Path, e.OldName, e.IsDir); err == nil {
+				base, ext := opts.splitName(e.NewName, e.IsDir)
+				e.NewName = e.limits(opts).fitName(base, "_"+opts.target().cased(h), ext, false)
+				if !taken(filepath.Join(dir, e.NewName)) {
+					e.AutoRenamed = true
+					assigned[key(e.NewName)] = true
+					continue
+				}
+				// Identical content: number the hashed name
+			}
+		}
+		_, e.NewName, e.Suffix = makeUnique(dir, e.NewName, e.limits(opts), taken)
+		e.AutoRenamed = true
+		assigned[key(e.NewName)] = true
+	}
+
+	kept := active[:0:0]
+	for _, e := range active {
+		if !e.dropped() {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+// batch_test.go
+// --------------
+// Unit tests for batch collision planning.
+// Tests cover deterministic suffix assignment and clashes with existing files.
+
+package clean
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// planNames returns the planned old -> new names of a plan.
+func planNames(p *Plan) map[string]string {
+	names := make(map[string]string)
+	for _, e := range p.Entries {
+		names[e.OldName] = e.NewName
+	}
+	return names
+}
+
+// TestBatchDeterministic tests that target order does not change the plan.
+func TestBatchDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := []string{"My File.txt", "my file.TXT", "MY  FILE.txt", "my_file.txt"}
+	touch(t, tmpDir, files...)
+
+	var forward, backward []string
+	for i := range files {
+		forward = append(forward, filepath.Join(tmpDir, files[i]))
+		backward = append(backward, filepath.Join(tmpDir, files[len(files)-1-i]))
+	}
+
+	opts := Options{Case: "lower"}
+	p1, _ := NewPlan(forward, opts)
+	p2, _ := NewPlan(backward, opts)
+
+	want := map[string]string{
+		// "my_file.txt" exists unchanged, so every other file gets a suffix,
+		// assigned in byte order of the original names
+		"MY  FILE.txt": "my_file_2.txt",
+		"My File.txt":  "my_file_3.txt",
+		"my file.TXT":  "my_file_4.txt",
+	}
+	for _, got := range []map[string]string{planNames(p1), planNames(p2)} {
+		if len(got) != len(want) {
+			t.Errorf("NewPlan() = %v, want %v", got, want)
+			continue
+		}
+		for old, newName := range want {
+			if got[old] != newName {
+				t.Errorf("NewPlan() %q -> %q, want %q", old, got[old], newName)
+			}
+		}
+	}
+}
+
+// TestBatchKeepsProposedNames tests that suffixes never take a name another entry proposed.
+func TestBatchKeepsProposedNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "a.txt", "A.TXT", "A 2.txt")
+
+	p, _ := NewPlan([]string{tmpDir}, Options{Case: "lower"})
+	got := planNames(p)
+
+	// "A 2.txt" proposes a_2.txt, so the clash of "A.TXT" with a.txt skips to _3
+	if got["A 2.txt"] != "a_2.txt" {
+		t.Errorf("NewPlan() %q -> %q, want %q", "A 2.txt", got["A 2.txt"], "a_2.txt")
+	}
+	if got["A.TXT"] != "a_3.txt" {
+		t.Errorf("NewPlan() %q -> %q, want %q", "A.TXT", got["A.TXT"], "a_3.txt")
+	}
+}
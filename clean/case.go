@@ -2,14 +2,16 @@
 // --------
 // Provides case transformations: lower, upper, and title.
 
-package main
+package clean
 
 import (
 	"unicode"
 	"unicode/utf8"
 )
 
-func toTitle(s string) string {
+// ToTitle upper-cases the first letter or digit of every word in s and
+// lower-cases the rest. Any non-alphanumeric rune starts a new word.
+func ToTitle(s string) string {
 	var out []rune
 	capNext := true
 	for len(s) > 0 {
@@ -0,0 +1,148 @@
+// cleaner.go
+// -----------
+// Filename normalization pipeline for Cleanfy.
+// Steps:
+// 1. Split extension
+// 2. Normalize to ASCII (NFKD)
+// 3. POSIX filtering
+// 4. Case transform
+// 5. Optional extension fix from the file content
+// 6. Optional extension canonicalization
+// 7. Dangerous name check
+// 8. Optional date prefix
+// 9. Reserved name protection
+// 10. Length limits
+//
+// Steps 2–10 are the built-in steps of DefaultPipeline.
+
+// Package clean implements Cleanfy's filename normalization and rename
+// planning. It is the library behind the cleanfy CLI: calling CleanName or
+// Walk with the same options produces exactly the names the CLI produces.
+package clean
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Names of the built-in pipeline steps.
+const (
+	StepASCII    = "ascii"
+	StepPosix    = "posix"
+	StepCase     = "case"
+	StepSniff    = "sniff"
+	StepExt      = "ext"
+	StepSecurity = "security"
+	StepDate     = "date"
+	StepReserved = "reserved"
+	StepLength   = "length"
+)
+
+// datePrefixRegex matches names that already start with a date prefix.
+var datePrefixRegex = regexp.MustCompile(`^(?:\d{4}[-_.\/]?\d{2}[-_.\/]?\d{2}|\d{6})[_\-\.]`)
+
+// DefaultPipeline returns a new pipeline with the built-in steps:
+// ascii, posix, case, sniff, ext, security, date, reserved and length.
+func DefaultPipeline() *Pipeline {
+	return NewPipeline(
+		NewStep(StepASCII, asciiStep),
+		NewStep(StepPosix, posixStep),
+		NewStep(StepCase, caseStep),
+		NewStep(StepSniff, sniffStep),
+		NewStep(StepExt, extStep),
+		NewStep(StepSecurity, securityStep),
+		NewStep(StepDate, dateStep),
+		NewStep(StepReserved, reservedStep),
+		NewStep(StepLength, lengthStep),
+	)
+}
+
+// CleanName returns the normalized form of name according to opts.
+// fullPath is the path of the entry on disk (used for the mtime date prefix)
+// and isDir disables extension handling for directories.
+// It runs opts.Pipeline, or DefaultPipeline when none is set.
+func CleanName(fullPath, name string, isDir bool, opts Options) (string, error) {
+	return opts.pipeline().Clean(fullPath, name, isDir, opts)
+}
+
+// ExplainName is like CleanName but also returns the intermediate result
+// of every pipeline step, for showing why a name was changed.
+func ExplainName(fullPath, name string, isDir bool, opts Options) (string, []Trace, error) {
+	return opts.pipeline().Explain(fullPath, name, isDir, opts)
+}
+
+// joinName is the inverse of Options.splitName.
+func joinName(base, ext string) string {
+	if ext == "" {
+		return base
+	}
+	return base + "." + ext
+}
+
+// Normalize to ASCII
+func asciiStep(_ *Context, base, ext string) (string, string, error) {
+	return CleanASCII(base), CleanASCII(ext), nil
+}
+
+// POSIX filtering, with the character set of the target
+func posixStep(ctx *Context, base, ext string) (string, string, error) {
+	disallowed := ctx.Opts.disallowed()
+	base = filterChars(base, disallowed)
+	if ext != "" {
+		ext = filterChars(ext, disallowed)
+	}
+	return base, ext, nil
+}
+
+// Apply case transformation
+func caseStep(ctx *Context, base, ext string) (string, string, error) {
+	switch strings.ToLower(ctx.Opts.Case) {
+	case "", "none":
+	// keep original case
+	case "lower":
+		base = strings.ToLower(base)
+	case "upper":
+		base = strings.ToUpper(base)
+	case "title":
+		base = ToTitle(base)
+	}
+	return ctx.Opts.target().cased(base), ctx.Opts.casedExt(ext), nil
+}
+
+// casedExt applies the case transform of o to an extension, also to one
+// added after the case step (--fix-ext). Title case leaves it unchanged.
+func (o Options) casedExt(ext string) string {
+	switch strings.ToLower(o.Case) {
+	case "lower":
+		ext = strings.ToLower(ext)
+	case "upper":
+		ext = strings.ToUpper(ext)
+	}
+	return o.target().cased(ext)
+}
+
+// 🧩 Add date prefix only when explicitly requested (-t or -date)
+func dateStep(ctx *Context, base, ext string) (string, string, error) {
+	if ctx.Opts.DateMode != "" && !datePrefixRegex.MatchString(base) {
+		if prefix := DatePrefix(ctx.Path, ctx.Opts.DateMode, ctx.Opts.dateFormat()); prefix != "" {
+			base = filterChars(prefix, ctx.Opts.disallowed()) + "_" + base
+		}
+	}
+	return base, ext, nil
+}
+
+// Prevent reserved names and trailing dots or spaces on the target
+func reservedStep(ctx *Context, base, ext string) (string, string, error) {
+	t := ctx.Opts.target()
+	if t.noTrailing {
+		base, ext = trimTrailing(base, ext)
+	}
+	name := joinName(base, ext)
+	if ctx.Hidden {
+		name = "." + name
+	}
+	if t.reserved != nil && t.reserved(name) {
+		base = "_" + base
+	}
+	return base, ext, nil
+}
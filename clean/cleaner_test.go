@@ -4,7 +4,7 @@
 // Tests cover ASCII conversion, POSIX filtering, case transformations,
 // and the full CleanName pipeline.
 
-package main
+package clean
 
 import (
 	"testing"
@@ -70,9 +70,9 @@ func TestCleanASCII(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cleanASCII(tt.input)
+			got := CleanASCII(tt.input)
 			if got != tt.expected {
-				t.Errorf("cleanASCII(%q) = %q, want %q", tt.input, got, tt.expected)
+				t.Errorf("CleanASCII(%q) = %q, want %q", tt.input, got, tt.expected)
 			}
 		})
 	}
@@ -136,9 +136,9 @@ func TestPosixify(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := posixify(tt.input)
+			got := Posixify(tt.input)
 			if got != tt.expected {
-				t.Errorf("posixify(%q) = %q, want %q", tt.input, got, tt.expected)
+				t.Errorf("Posixify(%q) = %q, want %q", tt.input, got, tt.expected)
 			}
 		})
 	}
@@ -167,25 +167,17 @@ func TestToTitle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := toTitle(tt.input)
+			got := ToTitle(tt.input)
 			if got != tt.expected {
-				t.Errorf("toTitle(%q) = %q, want %q", tt.input, got, tt.expected)
+				t.Errorf("ToTitle(%q) = %q, want %q", tt.input, got, tt.expected)
 			}
 		})
 	}
 }
 
 // TestCleanName tests the full pipeline: ASCII → POSIX → case transform → date prefix.
-// Note: We skip date prefixing tests here since they depend on flagDateMode and time.
+// Note: We skip date prefixing tests here since they depend on DateMode and time.
 func TestCleanName(t *testing.T) {
-	// Set up test flags
-	oldCase := flagCase
-	oldDateMode := flagDateMode
-	defer func() {
-		flagCase = oldCase
-		flagDateMode = oldDateMode
-	}()
-
 	tests := []struct {
 		name      string
 		fullPath  string
@@ -317,10 +309,9 @@ func TestCleanName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			flagCase = tt.caseMode
-			flagDateMode = tt.dateMode
+			opts := Options{Case: tt.caseMode, DateMode: tt.dateMode}
 
-			got, err := CleanName(tt.fullPath, tt.filename, tt.isDir)
+			got, err := CleanName(tt.fullPath, tt.filename, tt.isDir, opts)
 			if (err != nil) != tt.expectErr {
 				t.Errorf("CleanName() error = %v, expectErr %v", err, tt.expectErr)
 				return
@@ -338,7 +329,7 @@ func TestCleanName(t *testing.T) {
 func BenchmarkCleanASCII(b *testing.B) {
 	input := "Café Münster – Łódź с кириллицей"
 	for i := 0; i < b.N; i++ {
-		cleanASCII(input)
+		CleanASCII(input)
 	}
 }
 
@@ -346,16 +337,15 @@ func BenchmarkCleanASCII(b *testing.B) {
 func BenchmarkPosixify(b *testing.B) {
 	input := "file___name--with___dashes...and___dots"
 	for i := 0; i < b.N; i++ {
-		posixify(input)
+		Posixify(input)
 	}
 }
 
 // BenchmarkCleanName benchmarks the full pipeline.
 func BenchmarkCleanName(b *testing.B) {
-	flagCase = "lower"
-	flagDateMode = ""
+	opts := Options{Case: "lower"}
 	input := "My File Café – Münster.txt"
 	for i := 0; i < b.N; i++ {
-		CleanName("/tmp/test.txt", input, false)
+		CleanName("/tmp/test.txt", input, false, opts)
 	}
 }
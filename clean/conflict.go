@@ -0,0 +1,128 @@
+// conflict.go
+// ------------
+// Collision strategies for Cleanfy (--on-conflict).
+// A conflict occurs when a cleaned name is already taken in its directory,
+// by a file on disk or by another rename of the same run. The strategy
+// decides whether the entry gets a numeric or hash suffix, keeps its name,
+// fails, or replaces the existing file.
+
+package clean
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+)
+
+// Collision strategies for Options.OnConflict.
+const (
+	ConflictSuffix    = "suffix"    // Append a numeric suffix (default)
+	ConflictSkip      = "skip"      // Leave the entry unchanged
+	ConflictError     = "error"     // Report the entry as an error
+	ConflictOverwrite = "overwrite" // Replace the existing file
+	ConflictHash      = "hash"      // Append a short hash of the content
+	ConflictAsk       = "ask"       // Let Options.Ask decide per conflict
+)
+
+// DefaultSuffixFormat is the collision suffix layout used when
+// Options.SuffixFormat is empty.
+const DefaultSuffixFormat = "_%d"
+
+// DefaultSuffixStart is the first suffix number used when Options.SuffixStart is zero.
+const DefaultSuffixStart = 2
+
+// suffixFormatRE accepts layouts with exactly one integer verb, optionally
+// zero-padded, and no path separators.
+var suffixFormatRE = regexp.MustCompile(`^[^%/\\]*%(0[1-9])?d[^%/\\]*$`)
+
+// validateConflict checks the collision options of o.
+func (o Options) validateConflict() error {
+	switch o.OnConflict {
+	case "", ConflictSuffix, ConflictSkip, ConflictError, ConflictOverwrite, ConflictHash:
+	case ConflictAsk:
+		if o.Ask == nil {
+			return fmt.Errorf("on-conflict %q needs an Ask function", o.OnConflict)
+		}
+	default:
+		return fmt.Errorf("invalid on-conflict %q: use one of suffix | skip | error | overwrite | hash | ask", o.OnConflict)
+	}
+	if o.Atomic && o.OnConflict == ConflictOverwrite {
+		// A rollback cannot bring back an overwritten file
+		return fmt.Errorf("on-conflict %q cannot be used with atomic runs", o.OnConflict)
+	}
+	if o.SuffixFormat != "" && !suffixFormatRE.MatchString(o.SuffixFormat) {
+		return fmt.Errorf("invalid suffix format %q: use one %%d verb, e.g. _%%d, \" (%%d)\" or -%%03d", o.SuffixFormat)
+	}
+	if o.SuffixStart < 0 {
+		return fmt.Errorf("invalid suffix start %d: must not be negative", o.SuffixStart)
+	}
+	return nil
+}
+
+// suffix returns the collision suffix for number n.
+func (o Options) suffix(n int) string {
+	format := o.SuffixFormat
+	switch {
+	case o.ShortNames != "":
+		format = shortSets[o.ShortNames].suffix
+	case format == "":
+		format = DefaultSuffixFormat
+	}
+	return fmt.Sprintf(format, n)
+}
+
+// suffixStart returns the first suffix number.
+func (o Options) suffixStart() int {
+	switch {
+	case o.SuffixStart != 0:
+	case o.ShortNames != "":
+		return 1
+	default:
+		return DefaultSuffixStart
+	}
+	return o.SuffixStart
+}
+
+// conflictStrategy returns the strategy for a conflict of the entry at
+// path over name. With ConflictAsk, unknown answers leave the entry
+// unchanged, and overwriting fails the entry in atomic runs.
+func (o Options) conflictStrategy(path, name string) string {
+	switch o.OnConflict {
+	case "":
+		return ConflictSuffix
+	case ConflictAsk:
+		switch s := o.Ask(path, name); s {
+		case ConflictOverwrite:
+			if o.Atomic {
+				return ConflictError
+			}
+			return s
+		case ConflictSuffix, ConflictSkip, ConflictError, ConflictHash:
+			return s
+		}
+		return ConflictSkip
+	}
+	return o.OnConflict
+}
+
+// contentHash returns the first 8 hex digits of the SHA-256 of the file at
+// path. Directories are identified by name, since they have no content.
+func contentHash(path, name string, isDir bool) (string, error) {
+	h := sha256.New()
+	if isDir {
+		io.WriteString(h, name)
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8], nil
+}
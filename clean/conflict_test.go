@@ -0,0 +1,169 @@
+// conflict_test.go
+// -----------------
+// Unit tests for collision strategies (--on-conflict).
+// Tests cover every strategy, suffix layouts and option validation.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestConflictStrategies tests how "My File.txt" is handled when its
+// cleaned name "my_file.txt" already exists.
+func TestConflictStrategies(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantName   string // Name "My File.txt" ends up with
+		wantOld    string // Content left at my_file.txt
+		wantResult Result
+	}{
+		{
+			name:       "default",
+			opts:       Options{},
+			wantName:   "my_file_2.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file_2.txt", Renamed: true, AutoRenamed: true, Conflict: ConflictSuffix},
+		},
+		{
+			name:       "suffix-parens",
+			opts:       Options{OnConflict: ConflictSuffix, SuffixFormat: " (%d)", SuffixStart: 1},
+			wantName:   "my_file (1).txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file (1).txt", Renamed: true, AutoRenamed: true, Conflict: ConflictSuffix},
+		},
+		{
+			name:       "suffix-padded",
+			opts:       Options{SuffixFormat: "-%03d"},
+			wantName:   "my_file-002.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file-002.txt", Renamed: true, AutoRenamed: true, Conflict: ConflictSuffix},
+		},
+		{
+			name:       "skip",
+			opts:       Options{OnConflict: ConflictSkip},
+			wantName:   "My File.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file.txt", WasSkipped: true, Conflict: ConflictSkip},
+		},
+		{
+			name:       "error",
+			opts:       Options{OnConflict: ConflictError},
+			wantName:   "My File.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file.txt", Conflict: ConflictError, Error: "destination exists"},
+		},
+		{
+			name:       "overwrite",
+			opts:       Options{OnConflict: ConflictOverwrite},
+			wantName:   "my_file.txt",
+			wantResult: Result{NewName: "my_file.txt", Renamed: true, Conflict: ConflictOverwrite},
+		},
+		{
+			name: "hash",
+			opts: Options{OnConflict: ConflictHash},
+			// SHA-256 of "new"
+			wantName:   "my_file_11507a0e.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file_11507a0e.txt", Renamed: true, AutoRenamed: true, Conflict: ConflictHash},
+		},
+		{
+			name: "ask",
+			opts: Options{OnConflict: ConflictAsk, Ask: func(path, name string) string {
+				return ConflictSkip
+			}},
+			wantName:   "My File.txt",
+			wantOld:    "old",
+			wantResult: Result{NewName: "my_file.txt", WasSkipped: true, Conflict: ConflictSkip},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			os.WriteFile(filepath.Join(tmpDir, "my_file.txt"), []byte("old"), 0644)
+			path := filepath.Join(tmpDir, "My File.txt")
+			os.WriteFile(path, []byte("new"), 0644)
+			info, _ := os.Stat(path)
+
+			tt.opts.Case, tt.opts.Execute = "lower", true
+			r := ProcessOne(path, info, tt.opts)
+
+			want := tt.wantResult
+			if r.NewName != want.NewName || r.Renamed != want.Renamed || r.AutoRenamed != want.AutoRenamed ||
+				r.WasSkipped != want.WasSkipped || r.Conflict != want.Conflict || r.Error != want.Error {
+				t.Errorf("ProcessOne() = %+v, want %+v", r, want)
+			}
+			if b, err := os.ReadFile(filepath.Join(tmpDir, tt.wantName)); err != nil || string(b) != "new" {
+				t.Errorf("%q holds %q (%v), want %q", tt.wantName, b, err, "new")
+			}
+			if tt.wantOld != "" {
+				if b, _ := os.ReadFile(filepath.Join(tmpDir, "my_file.txt")); string(b) != tt.wantOld {
+					t.Errorf("my_file.txt holds %q, want %q", b, tt.wantOld)
+				}
+			}
+		})
+	}
+}
+
+// TestConflictSkipFreesName tests that a skipped entry keeps its name
+// occupied, so the entry that wanted it is resolved again.
+func TestConflictSkipFreesName(t *testing.T) {
+	tmpDir := t.TempDir()
+	// "b.txt" wants the taken "a.txt" and is skipped, so "B.txt" can no
+	// longer move into the name "b.txt" would have vacated
+	touch(t, tmpDir, "a.txt", "b.txt", "B.txt")
+
+	p := NewPipeline(NewStep("shift", func(_ *Context, base, ext string) (string, string, error) {
+		switch base {
+		case "b":
+			return "a", ext, nil
+		case "B":
+			return "b", ext, nil
+		}
+		return base, ext, nil
+	}))
+	plan, results := NewPlan([]string{tmpDir}, Options{OnConflict: ConflictSkip, Pipeline: p})
+
+	if len(plan.Entries) != 0 {
+		t.Errorf("NewPlan() planned %v, want nothing", planNames(plan))
+	}
+	for _, r := range results {
+		if r.NewName != r.OldName && r.Conflict != ConflictSkip {
+			t.Errorf("NewPlan() %q -> %q: Conflict = %q, want %q", r.OldName, r.NewName, r.Conflict, ConflictSkip)
+		}
+	}
+}
+
+// TestValidateConflict tests validation of the collision options.
+func TestValidateConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"default", Options{}, false},
+		{"hash", Options{OnConflict: ConflictHash}, false},
+		{"unknown", Options{OnConflict: "rename"}, true},
+		{"ask-without-func", Options{OnConflict: ConflictAsk}, true},
+		{"parens", Options{SuffixFormat: " (%d)"}, false},
+		{"padded", Options{SuffixFormat: "-%03d"}, false},
+		{"no-verb", Options{SuffixFormat: "_"}, true},
+		{"two-verbs", Options{SuffixFormat: "%d_%d"}, true},
+		{"string-verb", Options{SuffixFormat: "_%s"}, true},
+		{"separator", Options{SuffixFormat: "/%d"}, true},
+		{"negative-start", Options{SuffixStart: -1}, true},
+		{"atomic-overwrite", Options{Atomic: true, OnConflict: ConflictOverwrite}, true},
+		{"atomic-ask", Options{Atomic: true, OnConflict: ConflictAsk, Ask: func(string, string) string { return "" }}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// danger.go
+// ----------
+// Dangerous name detection for Cleanfy (--on-dangerous).
+// Cleaning keeps inner dots, so disguised executables such as
+// invoice.pdf.exe survive it. The security step flags executable
+// extensions hidden behind document extensions, text direction overrides
+// (invoice<U+202E>fdp.exe shows as invoiceexe.pdf) and spaces that push the
+// real extension out of view, and warns, renames, skips or fails the entry.
+
+package clean
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Dangerous name handling for Options.OnDangerous.
+const (
+	DangerousWarn   = "warn"   // Clean the name as usual and flag it (default)
+	DangerousRename = "rename" // Make the real extension the only one: invoice_pdf.exe
+	DangerousSkip   = "skip"   // Leave the entry unchanged
+	DangerousError  = "error"  // Report the entry as an error
+)
+
+// executableExts holds extensions that run code when opened, lower case.
+var executableExts = map[string]bool{
+	"exe": true, "com": true, "scr": true, "pif": true, "bat": true, "cmd": true,
+	"cpl": true, "msi": true, "msp": true, "msc": true, "vb": true, "vbs": true,
+	"vbe": true, "js": true, "jse": true, "wsf": true, "wsh": true, "hta": true,
+	"ps1": true, "psm1": true, "jar": true, "lnk": true, "reg": true, "scf": true,
+	"url": true, "application": true, "gadget": true, "app": true, "command": true,
+}
+
+// decoyExts holds extensions of documents and media that an executable
+// may hide behind: those of the detectable types, plus text formats.
+var decoyExts = map[string]bool{
+	"doc": true, "xls": true, "ppt": true, "rtf": true, "txt": true, "csv": true,
+	"htm": true, "html": true, "xml": true, "json": true, "md": true, "bmp": true,
+	"ico": true, "svg": true, "eml": true, "msg": true, "ics": true, "vcf": true,
+}
+
+func init() {
+	for _, t := range fileTypes {
+		if t.ext == "exe" {
+			continue
+		}
+		for _, ext := range append([]string{t.ext}, t.aliases...) {
+			if !executableExts[ext] {
+				decoyExts[ext] = true
+			}
+		}
+	}
+}
+
+// validateDangerous checks the dangerous name handling of o.
+func (o Options) validateDangerous() error {
+	switch o.OnDangerous {
+	case "", DangerousWarn, DangerousRename, DangerousSkip, DangerousError:
+		return nil
+	}
+	return fmt.Errorf("invalid on-dangerous %q: use one of warn | rename | skip | error", o.OnDangerous)
+}
+
+// onDangerous returns the dangerous name handling, DangerousWarn by default.
+func (o Options) onDangerous() string {
+	if o.OnDangerous == "" {
+		return DangerousWarn
+	}
+	return o.OnDangerous
+}
+
+// isBidiControl reports whether r changes the display direction of text.
+func isBidiControl(r rune) bool {
+	switch {
+	case r == '\u061c', r == '\u200e', r == '\u200f':
+		return true
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+		return true
+	}
+	return false
+}
+
+// CheckDangerousName returns an error if name looks like a disguised
+// executable: it contains a text direction override, or its executable
+// extension follows a document extension (invoice.pdf.exe), spaces
+// (photo.jpg   .scr) or is followed by trailing dots or spaces
+// (setup.exe ). Windows ignores the trailing ones when opening the file.
+func CheckDangerousName(name string) error {
+	if i := strings.IndexFunc(name, isBidiControl); i >= 0 {
+		r := []rune(name[i:])[0]
+		return fmt.Errorf("text direction override %U in %q", r, name)
+	}
+
+	trimmed := strings.TrimRightFunc(name, func(r rune) bool { return r == '.' || unicode.IsSpace(r) })
+	i := strings.LastIndexByte(trimmed, '.')
+	if i < 0 {
+		return nil
+	}
+	ext := strings.ToLower(trimmed[i+1:])
+	if !executableExts[ext] {
+		return nil
+	}
+	before := trimmed[:i]
+	if j := strings.LastIndexByte(before, '.'); j >= 0 {
+		if decoy := strings.ToLower(strings.TrimSpace(before[j+1:])); decoyExts[decoy] {
+			return fmt.Errorf("executable .%s hidden behind .%s", ext, decoy)
+		}
+	}
+	switch {
+	case strings.TrimRightFunc(before, unicode.IsSpace) != before:
+		return fmt.Errorf("spaces before executable extension .%s", ext)
+	case trimmed != name:
+		return fmt.Errorf("trailing dot or space after executable extension .%s", ext)
+	}
+	return nil
+}
+
+// hidesExecutable reports whether the entry of ctx holds a Windows
+// executable (found with --fix-ext) but has another extension.
+func hidesExecutable(ctx *Context, ext string) bool {
+	if ctx.Detected != "exe" || ext == "" || executableExts[strings.ToLower(ext)] {
+		return false
+	}
+	for _, t := range fileTypes {
+		if t.ext == "exe" {
+			return !t.accepts(ext)
+		}
+	}
+	return true
+}
+
+// Flag disguised executables, by name or by content (--fix-ext).
+// Cleaning can itself reveal a double extension (report.pdf!.exe becomes
+// report.pdf.exe), so the cleaned name is checked as well.
+func securityStep(ctx *Context, base, ext string) (string, string, error) {
+	err := CheckDangerousName(ctx.Name)
+	if err == nil {
+		name := joinName(base, ext)
+		if ctx.Hidden {
+			name = "." + name
+		}
+		err = CheckDangerousName(name)
+	}
+	if err == nil && hidesExecutable(ctx, ext) {
+		err = fmt.Errorf("executable content behind .%s", ext)
+	}
+	if err == nil {
+		return base, ext, nil
+	}
+	ctx.Dangerous = err.Error()
+
+	switch ctx.Opts.onDangerous() {
+	case DangerousError:
+		return base, ext, err
+	case DangerousRename:
+		// Inner dots would leave the fake extension in place
+		base = strings.ReplaceAll(base, ".", "_")
+		if hidesExecutable(ctx, ext) {
+			base, ext = base+"_"+ext, ctx.Opts.casedExt("exe")
+		}
+	}
+	return base, ext, nil
+}
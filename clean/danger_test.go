@@ -0,0 +1,125 @@
+// danger_test.go
+// ---------------
+// Unit tests for dangerous name detection (--on-dangerous).
+// Tests cover double extensions, text direction overrides, space tricks,
+// executable content and the four handling modes.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckDangerousName tests which names are taken as disguised executables.
+func TestCheckDangerousName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr string // Part of the error, "" for safe names
+	}{
+		{"invoice.pdf.exe", "executable .exe hidden behind .pdf"},
+		{"Invoice.PDF.EXE", "executable .exe hidden behind .pdf"},
+		{"photo.jpg .scr", "executable .scr hidden behind .jpg"},
+		{"report.docx.js", "executable .js hidden behind .docx"},
+		{"invoice\u202efdp.exe", "text direction override U+202E"},
+		{"notes\u2067txt.exe", "text direction override U+2067"},
+		{"readme          .exe", "spaces before executable extension .exe"},
+		{"setup.exe ", "trailing dot or space after executable extension .exe"},
+		{"setup.exe.", "trailing dot or space after executable extension .exe"},
+		{"setup.exe", ""},
+		{"setup.v2.exe", ""},
+		{"invoice.pdf", ""},
+		{"archive.tar.gz", ""},
+		{"my file .pdf", ""},
+		{"library.jar", ""},
+	}
+
+	for _, tt := range tests {
+		err := CheckDangerousName(tt.name)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("CheckDangerousName(%q) error = %v, want nil", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("CheckDangerousName(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestOnDangerous tests the handling modes for dangerous names.
+func TestOnDangerous(t *testing.T) {
+	tests := []struct {
+		name        string
+		onDangerous string
+		wantName    string
+		wantSkipped bool
+		wantErr     bool
+	}{
+		{"invoice.pdf.exe", "", "invoice.pdf.exe", false, false},
+		{"invoice.pdf.exe", DangerousWarn, "invoice.pdf.exe", false, false},
+		{"invoice.pdf.exe", DangerousRename, "invoice_pdf.exe", false, false},
+		{"photo.jpg .scr", DangerousRename, "photo_jpg.scr", false, false},
+		{"invoice.pdf.exe", DangerousSkip, "invoice.pdf.exe", true, false},
+		{"Photo 1.jpg .scr", DangerousSkip, "Photo 1.jpg .scr", true, false},
+		{"invoice.pdf.exe", DangerousError, "", false, true},
+		{"My Invoice.pdf", DangerousError, "My_Invoice.pdf", false, false},
+		// Cleaning reveals the double extension
+		{"report.pdf!.exe", DangerousError, "", false, true},
+		{"invoice.pdf\u200b.exe", DangerousError, "", false, true},
+		{"photo.jpg\u00ad.scr", DangerousError, "", false, true},
+		{"report.pdf!.exe", DangerousRename, "report_pdf.exe", false, false},
+		{"photo.jpg\u00ad.scr", DangerousWarn, "photo.jpg.scr", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.onDangerous, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			touch(t, tmpDir, tt.name)
+			path := filepath.Join(tmpDir, tt.name)
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatalf("failed to stat test file: %v", err)
+			}
+
+			r := ProcessOne(path, info, Options{OnDangerous: tt.onDangerous})
+			if r.NewName != tt.wantName || r.WasSkipped != tt.wantSkipped || r.HasError() != tt.wantErr {
+				t.Errorf("ProcessOne() = %q (skipped %v, error %q), want %q (skipped %v, error %v)",
+					r.NewName, r.WasSkipped, r.Error, tt.wantName, tt.wantSkipped, tt.wantErr)
+			}
+			if safe := tt.name == "My Invoice.pdf"; (r.Dangerous == "") != safe {
+				t.Errorf("Dangerous = %q, want it set: %v", r.Dangerous, !safe)
+			}
+		})
+	}
+}
+
+// TestOnDangerousContent tests executables found with --fix-ext behind another extension.
+func TestOnDangerousContent(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "invoice.pdf")
+	if err := os.WriteFile(path, []byte("MZ"+strings.Repeat("\x00", 126)), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		t.Fatalf("failed to stat test file: %v", err)
+	}
+
+	r := ProcessOne(path, info, Options{FixExt: FixExtAdd, OnDangerous: DangerousRename})
+	if r.NewName != "invoice_pdf.exe" || r.Dangerous != "executable content behind .pdf" {
+		t.Errorf("ProcessOne() = %q (dangerous %q), want %q", r.NewName, r.Dangerous, "invoice_pdf.exe")
+	}
+}
+
+// TestOnDangerousValidate tests the accepted on-dangerous values.
+func TestOnDangerousValidate(t *testing.T) {
+	for _, mode := range []string{"", DangerousWarn, DangerousRename, DangerousSkip, DangerousError} {
+		if err := (Options{OnDangerous: mode}).Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", mode, err)
+		}
+	}
+	if err := (Options{OnDangerous: "ignore"}).Validate(); err == nil {
+		t.Errorf("Validate(ignore) error = nil, want error")
+	}
+}
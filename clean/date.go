@@ -4,16 +4,16 @@
 // Supports modes: mtime (last modified) and now (current time).
 // Uses Go's time formatting layout, defaulting to ISO 8601 (2006-01-02).
 
-package main
+package clean
 
 import (
 	"os"
 	"time"
 )
 
-// getDatePrefix returns a formatted date string for a given file
+// DatePrefix returns a formatted date string for a given file
 // based on the selected mode: "mtime" or "now".
-func getDatePrefix(path string, mode string, layout string) string {
+func DatePrefix(path string, mode string, layout string) string {
 	var t time.Time
 
 	switch mode {
@@ -0,0 +1,140 @@
+// extension.go
+// -------------
+// Extension handling for Cleanfy.
+// Multi-part extensions such as .tar.gz are split off as one unit, so
+// casing, truncation and collision suffixes never land between their
+// parts (backup_2.tar.gz, not backup.tar_2.gz). With --normalize-ext,
+// extensions are canonicalized (.JPEG, .jpe → .jpg), so downstream tools
+// can match on a single extension per type.
+
+package clean
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultCompoundExts returns the multi-part extensions (without the
+// leading dot) recognized when Options.CompoundExts is nil.
+func DefaultCompoundExts() []string {
+	return []string{
+		"tar.gz", "tar.bz2", "tar.xz", "tar.zst", "tar.lz", "tar.lzma",
+		"nii.gz", "user.js", "user.css", "min.js", "min.css", "d.ts",
+	}
+}
+
+// defaultCompoundExts is shared by all Options without CompoundExts.
+var defaultCompoundExts = DefaultCompoundExts()
+
+// compoundExts returns the multi-part extensions recognized by o.
+func (o Options) compoundExts() []string {
+	if o.singleDot() {
+		return nil
+	}
+	if o.CompoundExts == nil {
+		return defaultCompoundExts
+	}
+	return o.CompoundExts
+}
+
+// DefaultExtMap returns the canonical form of common alternative
+// extensions, used by Options.NormalizeExt.
+func DefaultExtMap() map[string]string {
+	return map[string]string{
+		"jpeg": "jpg", "jpe": "jpg", "jfif": "jpg",
+		"tiff": "tif",
+		"htm":  "html",
+		"yml":  "yaml",
+		"mpeg": "mpg", "mpe": "mpg",
+		"markdown": "md", "mdown": "md", "mkd": "md",
+		"midi": "mid",
+	}
+}
+
+// defaultExtMap is shared by all Options.
+var defaultExtMap = DefaultExtMap()
+
+// validateExts checks the extension options of o.
+func (o Options) validateExts() error {
+	for _, ext := range o.CompoundExts {
+		if !validExt(ext) {
+			return fmt.Errorf("invalid compound extension %q: use parts separated by dots, e.g. tar.gz", ext)
+		}
+	}
+	for from, to := range o.ExtMap {
+		if !validExt(from) || !validExt(to) {
+			return fmt.Errorf("invalid extension mapping %q -> %q: use extensions without the leading dot, e.g. tiff -> tif", from, to)
+		}
+	}
+	return nil
+}
+
+// validExt reports whether ext is an extension without the leading dot.
+func validExt(ext string) bool {
+	return ext != "" && !strings.HasPrefix(ext, ".") && !strings.HasSuffix(ext, ".") && !strings.ContainsAny(ext, `/\`)
+}
+
+// canonicalExt returns the canonical form of ext: lower case, mapped by
+// o.ExtMap or else DefaultExtMap. Extensions that o.ExtMap maps to are
+// canonical, so overrides are not undone by the default map, and results
+// of the default map are mapped by o.ExtMap once more (jpe → jpg → jpeg).
+func (o Options) canonicalExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if to, ok := o.mapExt(ext); ok {
+		return to
+	}
+	for _, to := range o.ExtMap {
+		if strings.EqualFold(to, ext) {
+			return ext
+		}
+	}
+	if to, ok := defaultExtMap[ext]; ok {
+		if override, ok := o.mapExt(to); ok {
+			return override
+		}
+		return to
+	}
+	return ext
+}
+
+// mapExt returns the lower case mapping of ext in o.ExtMap, if any.
+func (o Options) mapExt(ext string) (string, bool) {
+	for from, to := range o.ExtMap {
+		if strings.EqualFold(from, ext) {
+			return strings.ToLower(to), true
+		}
+	}
+	return "", false
+}
+
+// Canonicalize the extension, regardless of the case transform
+func extStep(ctx *Context, base, ext string) (string, string, error) {
+	if !ctx.Opts.NormalizeExt || ext == "" {
+		return base, ext, nil
+	}
+	return base, ctx.Opts.target().cased(ctx.Opts.canonicalExt(ext)), nil
+}
+
+// splitName splits name into base and extension (without the dot). The
+// longest known multi-part extension matching the end of name, in any
+// case, is split off whole; otherwise the split is at the last dot.
+// Directories have no extension.
+func (o Options) splitName(name string, isDir bool) (string, string) {
+	if isDir {
+		return name, ""
+	}
+	lower := strings.ToLower(name)
+	best := 0
+	for _, ext := range o.compoundExts() {
+		if len(ext) > best && len(name) > len(ext)+1 && strings.HasSuffix(lower, "."+strings.ToLower(ext)) {
+			best = len(ext)
+		}
+	}
+	if best > 0 {
+		return name[:len(name)-best-1], name[len(name)-best:]
+	}
+	if i := strings.LastIndexByte(name, '.'); i > 0 && i < len(name)-1 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
@@ -0,0 +1,151 @@
+// extension_test.go
+// ------------------
+// Unit tests for extension handling.
+// Tests cover multi-part extensions in the split, casing, truncation and
+// collision suffixes, and extension normalization.
+
+package clean
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestSplitNameCompound tests splitting off multi-part extensions.
+func TestSplitNameCompound(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantBase string
+		wantExt  string
+	}{
+		{"backup.tar.gz", Options{}, "backup", "tar.gz"},
+		{"Backup 2024.TAR.GZ", Options{}, "Backup 2024", "TAR.GZ"},
+		{"scan.nii.gz", Options{}, "scan", "nii.gz"},
+		{"script.user.js", Options{}, "script", "user.js"},
+		{"data.gz", Options{}, "data", "gz"},
+		{"notes.txt", Options{}, "notes", "txt"},
+		{"tar.gz", Options{}, "tar", "gz"},
+		{"linux.pkg.tar.zst", Options{}, "linux.pkg", "tar.zst"},
+		{"linux.pkg.tar.zst", Options{CompoundExts: []string{"tar.zst", "pkg.tar.zst"}}, "linux", "pkg.tar.zst"},
+		{"backup.tar.gz", Options{CompoundExts: []string{}}, "backup.tar", "gz"},
+		{"backup.tar.gz", Options{ShortNames: ShortDOS}, "backup.tar", "gz"},
+	}
+
+	for _, tt := range tests {
+		base, ext := tt.opts.splitName(tt.name, false)
+		if base != tt.wantBase || ext != tt.wantExt {
+			t.Errorf("splitName(%q) = %q, %q, want %q, %q", tt.name, base, ext, tt.wantBase, tt.wantExt)
+		}
+	}
+}
+
+// TestCleanNameCompoundExt tests that multi-part extensions are cased and
+// kept whole as one unit.
+func TestCleanNameCompoundExt(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		want  string
+	}{
+		{"Backup 2024.TAR.GZ", Options{Case: "title"}, "Backup_2024.TAR.GZ"},
+		{"Backup 2024.TAR.GZ", Options{Case: "lower"}, "backup_2024.tar.gz"},
+		{"very long backup name.tar.gz", Options{MaxLength: 16}, "very_long.tar.gz"},
+		{"Greasemonkey Script.USER.JS", Options{Case: "lower"}, "greasemonkey_script.user.js"},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanName("/tmp/"+tt.input, tt.input, false, tt.opts)
+		if err != nil {
+			t.Fatalf("CleanName(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("CleanName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestCompoundExtSuffix tests that collision suffixes go before the whole extension.
+func TestCompoundExtSuffix(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "backup.tar.gz", "Backup.tar.gz")
+
+	p, _ := NewPlan([]string{filepath.Join(tmpDir, "Backup.tar.gz")}, Options{Case: "lower", FSCase: FSCaseSensitive})
+	if got := planNames(p)["Backup.tar.gz"]; got != "backup_2.tar.gz" {
+		t.Errorf("NewPlan() -> %q, want %q", got, "backup_2.tar.gz")
+	}
+}
+
+// TestCompoundExtsValidate tests the accepted compound extension forms.
+func TestCompoundExtsValidate(t *testing.T) {
+	tests := []struct {
+		exts    []string
+		wantErr bool
+	}{
+		{[]string{"tar.gz", "pkg.tar.zst"}, false},
+		{[]string{""}, true},
+		{[]string{".tar.gz"}, true},
+		{[]string{"tar.gz."}, true},
+		{[]string{"tar/gz"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := (Options{CompoundExts: tt.exts}).Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.exts, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCleanNameNormalizeExt tests extension canonicalization independent of the case transform.
+func TestCleanNameNormalizeExt(t *testing.T) {
+	tests := []struct {
+		input string
+		opts  Options
+		want  string
+	}{
+		{"IMG 0001.JPEG", Options{NormalizeExt: true}, "IMG_0001.jpg"},
+		{"IMG 0001.Jpe", Options{NormalizeExt: true}, "IMG_0001.jpg"},
+		{"IMG 0001.JPG", Options{NormalizeExt: true}, "IMG_0001.jpg"},
+		{"IMG 0001.JPEG", Options{NormalizeExt: true, Case: "upper"}, "IMG_0001.jpg"},
+		{"IMG 0001.JPEG", Options{Case: "upper"}, "IMG_0001.JPEG"},
+		{"scan.TIF", Options{NormalizeExt: true, ExtMap: map[string]string{"tif": "tiff"}}, "scan.tiff"},
+		{"scan.tiff", Options{NormalizeExt: true, ExtMap: map[string]string{"tif": "tiff"}}, "scan.tiff"},
+		{"y.jpg", Options{NormalizeExt: true, ExtMap: map[string]string{"jpg": "jpeg"}}, "y.jpeg"},
+		{"x.jpe", Options{NormalizeExt: true, ExtMap: map[string]string{"jpg": "jpeg"}}, "x.jpeg"},
+		{"w.JFIF", Options{NormalizeExt: true, ExtMap: map[string]string{"jpg": "jpeg"}}, "w.jpeg"},
+		{"v.JPEG", Options{NormalizeExt: true, ExtMap: map[string]string{"jpg": "jpeg"}}, "v.jpeg"},
+		{"config.YML", Options{NormalizeExt: true}, "config.yaml"},
+		{"backup.TAR.GZ", Options{NormalizeExt: true}, "backup.tar.gz"},
+		{"photo.jpeg", Options{NormalizeExt: true, Target: TargetISO9660}, "PHOTO.JPG"},
+		{"Photos", Options{NormalizeExt: true}, "Photos"},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanName("/tmp/"+tt.input, tt.input, false, tt.opts)
+		if err != nil {
+			t.Fatalf("CleanName(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("CleanName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestExtMapValidate tests the accepted extension mappings.
+func TestExtMapValidate(t *testing.T) {
+	tests := []struct {
+		extMap  map[string]string
+		wantErr bool
+	}{
+		{map[string]string{"tif": "tiff", "jpeg": "jpg"}, false},
+		{map[string]string{"": "jpg"}, true},
+		{map[string]string{"jpeg": ""}, true},
+		{map[string]string{".jpeg": "jpg"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := (Options{NormalizeExt: true, ExtMap: tt.extMap}).Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.extMap, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+// fileid_other.go
+// ----------------
+// Fallback for systems without device/inode numbers in file info.
+
+//go:build !unix
+
+package clean
+
+import "os"
+
+// fileID returns zeros: device and inode numbers are not available.
+func fileID(info os.FileInfo) (dev, ino uint64) {
+	return 0, 0
+}
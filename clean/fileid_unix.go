@@ -0,0 +1,20 @@
+// fileid_unix.go
+// ---------------
+// Reads device and inode numbers from file info on Unix systems.
+
+//go:build unix
+
+package clean
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode number of info, or zeros if unavailable.
+func fileID(info os.FileInfo) (dev, ino uint64) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Dev), uint64(st.Ino)
+	}
+	return 0, 0
+}
@@ -0,0 +1,82 @@
+// fscase.go
+// ----------
+// Case-insensitive filesystem awareness for Cleanfy (--fs-case).
+// On exFAT/vfat drives, Samba shares and default macOS volumes,
+// "Report.pdf" and "report.pdf" name the same file. In such directories
+// names collide when their case-folded forms match, and case-only renames
+// go through a temporary name.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Filesystem case modes for Options.FSCase.
+const (
+	FSCaseAuto        = "auto"        // Detect per directory (default)
+	FSCaseSensitive   = "sensitive"   // Names differing in case are distinct
+	FSCaseInsensitive = "insensitive" // Names differing in case are the same file
+)
+
+// caseInsensitive reports whether names in dir must be compared
+// case-insensitively. In auto mode, names also collide when they would
+// on the target or, with short names, on FAT and ISO 9660.
+func (o Options) caseInsensitive(dir string) bool {
+	switch o.FSCase {
+	case FSCaseSensitive:
+		return false
+	case FSCaseInsensitive:
+		return true
+	}
+	return o.target().caseInsensitive || o.ShortNames != "" || detectCaseInsensitive(dir)
+}
+
+// detectCaseInsensitive probes dir without writing to it: it looks up an
+// existing entry under a different case and checks whether that resolves
+// to the same file. Directories without any cased name are reported as
+// case-sensitive.
+func detectCaseInsensitive(dir string) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false
+	}
+	names, _ := f.Readdirnames(64)
+	f.Close()
+
+	for _, name := range names {
+		other := swapCase(name)
+		if other == name {
+			continue
+		}
+		a, err := os.Lstat(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		b, err := os.Lstat(filepath.Join(dir, other))
+		return err == nil && os.SameFile(a, b)
+	}
+	return false
+}
+
+// swapCase turns upper-case letters into lower case and vice versa.
+func swapCase(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+		return unicode.ToUpper(r)
+	}, s)
+}
+
+// nameKey returns the form of name used to detect collisions: the name
+// itself, or its case-folded form when fold is set.
+func nameKey(name string, fold bool) string {
+	if fold {
+		return strings.ToLower(name)
+	}
+	return name
+}
@@ -0,0 +1,64 @@
+// fscase_test.go
+// ---------------
+// Unit tests for case-insensitive filesystem handling (--fs-case).
+// The test filesystem is case-sensitive, so insensitivity is forced.
+
+package clean
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestDetectCaseInsensitive tests detection on the (case-sensitive) temp directory.
+func TestDetectCaseInsensitive(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "Report.pdf")
+
+	if detectCaseInsensitive(tmpDir) {
+		t.Errorf("detectCaseInsensitive(%q) = true, want false", tmpDir)
+	}
+	if !(Options{FSCase: FSCaseInsensitive}).caseInsensitive(tmpDir) {
+		t.Errorf("caseInsensitive() ignores FSCase %q", FSCaseInsensitive)
+	}
+}
+
+// TestFSCaseCollision tests that names differing only in case collide
+// on case-insensitive filesystems only.
+func TestFSCaseCollision(t *testing.T) {
+	tests := []struct {
+		fsCase string
+		want   string
+	}{
+		{FSCaseSensitive, "Report.pdf"},
+		{FSCaseInsensitive, "Report_2.pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fsCase, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			touch(t, tmpDir, "report.pdf", "Report .pdf")
+
+			p, _ := NewPlan([]string{tmpDir}, Options{FSCase: tt.fsCase})
+			if got := planNames(p)["Report .pdf"]; got != tt.want {
+				t.Errorf("NewPlan() %q -> %q, want %q", "Report .pdf", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestFSCaseOnlyRename tests that a case-only rename keeps its name and
+// is executed, rather than colliding with itself.
+func TestFSCaseOnlyRename(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "README.MD")
+
+	results := Walk([]string{tmpDir}, Options{Case: "lower", FSCase: FSCaseInsensitive, Execute: true})
+
+	if len(results) != 1 || !results[0].Renamed || results[0].AutoRenamed || results[0].HasError() {
+		t.Fatalf("Walk() = %+v, want a plain rename", results)
+	}
+	if results[0].Path != filepath.Join(tmpDir, "readme.md") {
+		t.Errorf("Walk() Path = %q, want %q", results[0].Path, filepath.Join(tmpDir, "readme.md"))
+	}
+	checkContent(t, tmpDir, map[string]string{"readme.md": "README.MD"})
+}
@@ -0,0 +1,238 @@
+// journal.go
+// -----------
+// Rename journal and undo for Cleanfy.
+// Every executed rename can be appended to a JSON Lines journal
+// (old path, new path, time, fingerprint), grouped by run.
+// Undo reverses the renames of a run in reverse order.
+
+package clean
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JournalEntry records one executed rename.
+type JournalEntry struct {
+	Run         string      `json:"run"`               // ID of the run that performed the rename
+	UndoOf      string      `json:"undo_of,omitempty"` // ID of the run this rename reverses (undo runs only)
+	Time        time.Time   `json:"time"`              // Time of the rename
+	OldPath     string      `json:"old_path"`          // Absolute path before the rename
+	NewPath     string      `json:"new_path"`          // Absolute path after the rename
+	IsDir       bool        `json:"is_dir"`            // True if the entry is a directory
+	Fingerprint Fingerprint `json:"fingerprint"`       // Identity of the entry right after the rename
+}
+
+// Journal appends executed renames to a journal file.
+// The file is created on the first recorded rename.
+// A Journal is safe for concurrent use.
+type Journal struct {
+	path   string
+	run    string
+	undoOf string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewJournal returns a journal appending to path under a new run ID.
+func NewJournal(path string) *Journal {
+	return &Journal{path: path, run: newRunID()}
+}
+
+// DefaultJournalPath returns the journal location used by the CLI:
+// cleanfy/journal.jsonl in the user's configuration directory.
+func DefaultJournalPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cleanfy", "journal.jsonl"), nil
+}
+
+// newRunID returns a unique, sortable ID for a run.
+func newRunID() string {
+	return fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102T150405.000000000Z"), os.Getpid())
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() string { return j.path }
+
+// Run returns the ID under which renames are recorded.
+func (j *Journal) Run() string { return j.run }
+
+// Record appends a rename from oldPath to newPath. It must be called
+// after the rename succeeded, so the entry can be fingerprinted.
+func (j *Journal) Record(oldPath, newPath string) error {
+	oldAbs, err := filepath.Abs(oldPath)
+	if err != nil {
+		return err
+	}
+	newAbs, err := filepath.Abs(newPath)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(newAbs)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(JournalEntry{
+		Run:         j.run,
+		UndoOf:      j.undoOf,
+		Time:        time.Now(),
+		OldPath:     oldAbs,
+		NewPath:     newAbs,
+		IsDir:       info.IsDir(),
+		Fingerprint: fingerprintOf(info),
+	})
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return err
+		}
+		j.f = f
+	}
+	_, err = j.f.Write(append(b, '\n'))
+	return err
+}
+
+// Close closes the journal file if it was opened.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
+
+// record journals a successful rename, if opts has a journal.
+// A journal failure is returned as a Result error message.
+func (o Options) record(oldPath, newPath string) string {
+	if o.Journal == nil {
+		return ""
+	}
+	if err := o.Journal.Record(oldPath, newPath); err != nil {
+		return "renamed but not journaled: " + err.Error()
+	}
+	return ""
+}
+
+// ReadJournal loads all entries of a journal file in order.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
+
+// lastUndoableRun returns the most recent run that is neither an undo
+// run nor already undone.
+func lastUndoableRun(entries []JournalEntry) string {
+	undone := make(map[string]bool)
+	for _, e := range entries {
+		if e.UndoOf != "" {
+			undone[e.UndoOf] = true
+			undone[e.Run] = true
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !undone[entries[i].Run] {
+			return entries[i].Run
+		}
+	}
+	return ""
+}
+
+// Undo reverses the renames of run recorded in the journal at path,
+// newest first. An empty run selects the most recent run not yet undone.
+// Entries that were moved, deleted or modified since the rename, or whose
+// original path is occupied again, are reported as errors and left alone.
+// The reversing renames are appended to the same journal.
+func Undo(path, run string) []Result {
+	entries, err := ReadJournal(path)
+	if err != nil {
+		return []Result{{Path: path, Error: err.Error()}}
+	}
+	if run == "" {
+		run = lastUndoableRun(entries)
+	}
+	if run == "" {
+		return []Result{{Path: path, Error: "nothing to undo"}}
+	}
+
+	j := &Journal{path: path, run: newRunID(), undoOf: run}
+	defer j.Close()
+
+	var results []Result
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Run != run {
+			continue
+		}
+		r := Result{Path: e.NewPath, OldName: filepath.Base(e.NewPath), NewName: filepath.Base(e.OldPath), IsDir: e.IsDir}
+
+		info, err := os.Lstat(e.NewPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			r.Error = "moved or deleted since rename"
+		case err != nil:
+			r.Error = err.Error()
+		case !e.Fingerprint.matches(info):
+			r.Error = "modified since rename"
+		}
+		if r.Error == "" {
+			// Never replace a file created at the original path since the rename
+			if err := renameNoReplace(e.NewPath, e.OldPath); errors.Is(err, errDestinationExists) {
+				r.Error = "original path is occupied"
+			} else if err != nil {
+				r.Error = err.Error()
+			} else {
+				r.Path = e.OldPath
+				r.Renamed = true
+				if err := j.Record(e.NewPath, e.OldPath); err != nil {
+					r.Error = "renamed but not journaled: " + err.Error()
+				}
+			}
+		}
+		results = append(results, r)
+	}
+	if results == nil {
+		return []Result{{Path: path, Error: fmt.Sprintf("run %q not found", run)}}
+	}
+	return results
+}
@@ -0,0 +1,99 @@
+// journal_test.go
+// ----------------
+// Unit tests for the rename journal and undo.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestJournalUndo tests that a journaled run can be reversed exactly once.
+func TestJournalUndo(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "My File.txt", "Other.TXT")
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	j := NewJournal(journalFile)
+	results := Walk([]string{tmpDir}, Options{Case: "upper", Execute: true, Journal: j})
+	j.Close()
+	for _, r := range results {
+		if r.HasError() {
+			t.Fatalf("Walk() error for %q: %s", r.OldName, r.Error)
+		}
+	}
+
+	entries, err := ReadJournal(journalFile)
+	if err != nil {
+		t.Fatalf("ReadJournal() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ReadJournal() returned %d entries, want 2", len(entries))
+	}
+
+	for _, r := range Undo(journalFile, "") {
+		if r.HasError() || !r.Renamed {
+			t.Errorf("Undo() %q: Renamed = %v, Error = %q", r.OldName, r.Renamed, r.Error)
+		}
+	}
+	for _, name := range []string{"My File.txt", "Other.TXT"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("Undo() did not restore %q: %v", name, err)
+		}
+	}
+
+	// The undone run and the undo run itself are not undoable again
+	results = Undo(journalFile, "")
+	if len(results) != 1 || results[0].Error != "nothing to undo" {
+		t.Errorf("second Undo() = %+v, want nothing to undo", results)
+	}
+}
+
+// TestUndoModified tests that entries modified since the rename are left alone.
+func TestUndoModified(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt")
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	j := NewJournal(journalFile)
+	Walk([]string{tmpDir}, Options{Case: "lower", Execute: true, Journal: j})
+	j.Close()
+
+	renamed := filepath.Join(tmpDir, "a_file.txt")
+	if err := os.WriteFile(renamed, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := Undo(journalFile, "")
+	if len(results) != 1 || results[0].Error != "modified since rename" {
+		t.Errorf("Undo() = %+v, want modified since rename", results)
+	}
+	if _, err := os.Stat(renamed); err != nil {
+		t.Errorf("Undo() moved a modified file: %v", err)
+	}
+}
+
+// TestUndoOccupied tests that a file created at the original path since
+// the rename is never replaced.
+func TestUndoOccupied(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "A File.txt")
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	j := NewJournal(journalFile)
+	Walk([]string{tmpDir}, Options{Case: "lower", Execute: true, Journal: j})
+	j.Close()
+
+	// Another process creates a file under the original name
+	if err := os.WriteFile(filepath.Join(tmpDir, "A File.txt"), []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	results := Undo(journalFile, "")
+	if len(results) != 1 || results[0].Error != "original path is occupied" || results[0].Renamed {
+		t.Errorf("Undo() = %+v, want original path is occupied", results)
+	}
+	checkContent(t, tmpDir, map[string]string{"A File.txt": "new", "a_file.txt": "A File.txt"})
+}
@@ -0,0 +1,137 @@
+// length.go
+// ----------
+// Name length limits for Cleanfy (--max-length, --max-chars).
+// Names are shortened at the end of the base, on UTF-8 character
+// boundaries, so the extension, a leading date prefix and any collision
+// suffix are kept.
+
+package clean
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxLength is the name length limit in bytes used when
+// Options.MaxLength is zero. Most filesystems allow 255 bytes per name.
+const DefaultMaxLength = 255
+
+// minLength is the smallest accepted name length limit.
+const minLength = 8
+
+// truncHashLen is the number of hex digits of the hash appended to
+// shortened names with Options.TruncHash.
+const truncHashLen = 6
+
+// maxLength returns the name length limit in bytes.
+func (o Options) maxLength() int {
+	switch {
+	case o.MaxLength < 0:
+		return math.MaxInt
+	case o.MaxLength == 0:
+		return o.target().maxName
+	}
+	return o.MaxLength
+}
+
+// maxChars returns the name length limit in characters.
+func (o Options) maxChars() int {
+	if o.MaxChars <= 0 {
+		return math.MaxInt
+	}
+	return o.MaxChars
+}
+
+// fitName joins base, suffix and ext (without the dot) into a name within
+// the length limits of o, shortening base as needed. With hash set, a
+// shortened base ends in a short hash of the full base, so names cut to
+// the same prefix stay distinct. If the suffix and extension alone do not
+// fit, the extension is shortened too; the suffix is always kept.
+// A name cut down to a reserved one (CON_abcdefgh.txt to CON.txt) gets
+// the reserved name prefix, like in the reserved step.
+func (o Options) fitName(base, suffix, ext string, hash bool) string {
+	if o.ShortNames != "" {
+		return o.fitShort(base, suffix, ext)
+	}
+	name := o.fitLimits(base, suffix, ext, hash)
+	if reserved := o.target().reserved; reserved != nil && name != joinName(base+suffix, ext) && reserved(name) {
+		name = o.fitLimits("_"+base, suffix, ext, hash)
+	}
+	return name
+}
+
+// fitLimits is fitName without the reserved name check.
+func (o Options) fitLimits(base, suffix, ext string, hash bool) string {
+	name := joinName(base+suffix, ext)
+	maxBytes, maxChars := o.maxLength(), o.maxChars()
+	if len(name) <= maxBytes && utf8.RuneCountInString(name) <= maxChars {
+		return name
+	}
+
+	tail := joinName(suffix, ext)
+	if hash {
+		sum := sha256.Sum256([]byte(base))
+		tail = joinName("_"+o.target().cased(hex.EncodeToString(sum[:])[:truncHashLen])+suffix, ext)
+	}
+	if len(tail) >= maxBytes || utf8.RuneCountInString(tail) >= maxChars {
+		whole := joinName(base, ext)
+		return trimCut(truncate(whole, maxBytes-len(suffix), maxChars-utf8.RuneCountInString(suffix))) + suffix
+	}
+	// A leading date prefix is kept whole when it fits
+	prefix := datePrefixRegex.FindString(base)
+	if len(prefix)+len(tail) > maxBytes || utf8.RuneCountInString(prefix+tail) > maxChars {
+		prefix = ""
+	}
+	rest := strings.TrimPrefix(base, prefix)
+	cut := trimCut(prefix + truncate(rest, maxBytes-len(prefix)-len(tail), maxChars-utf8.RuneCountInString(prefix+tail)))
+	return cut + tail
+}
+
+// truncate shortens s to at most maxBytes bytes and maxChars characters,
+// never splitting a UTF-8 sequence.
+func truncate(s string, maxBytes, maxChars int) string {
+	chars := 0
+	for i, r := range s {
+		size := utf8.RuneLen(r)
+		if size < 0 {
+			size = 1 // Invalid byte, kept as is
+		}
+		if i+size > maxBytes || chars+1 > maxChars {
+			return s[:i]
+		}
+		chars++
+	}
+	return s
+}
+
+// trimCut removes separators left dangling at the end of a shortened name.
+func trimCut(s string) string {
+	return strings.TrimRight(s, "_-. ")
+}
+
+// Enforce name length limits, or 8.3 short names
+func lengthStep(ctx *Context, base, ext string) (string, string, error) {
+	if ctx.Opts.ShortNames != "" {
+		base, ext = ctx.Opts.shortName(base, ext)
+		return base, ext, nil
+	}
+	opts := ctx.Opts
+	if ctx.Hidden {
+		// Leave room for the leading dot
+		opts.MaxLength = opts.maxLength() - 1
+		if opts.MaxChars > 0 {
+			opts.MaxChars--
+		}
+	}
+	name := opts.fitName(base, "", ext, opts.TruncHash)
+	if name == joinName(base, ext) {
+		return base, ext, nil
+	}
+	if ext != "" && strings.HasSuffix(name, "."+ext) {
+		return strings.TrimSuffix(name, "."+ext), ext, nil
+	}
+	return name, "", nil
+}
@@ -0,0 +1,89 @@
+// length_test.go
+// ---------------
+// Unit tests for name length limits (--max-length, --max-chars).
+// Tests cover UTF-8-safe truncation and what is preserved when shortening.
+
+package clean
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFitName tests shortening names to the configured limits.
+func TestFitName(t *testing.T) {
+	sum := sha256.Sum256([]byte("abcdefghijklmnopqrstuvwxyz"))
+	hash := hex.EncodeToString(sum[:])[:truncHashLen]
+	sum = sha256.Sum256([]byte("2024-01-02_A_Very_Long_Title"))
+	dateHash := hex.EncodeToString(sum[:])[:truncHashLen]
+
+	tests := []struct {
+		name              string
+		opts              Options
+		base, suffix, ext string
+		hash              bool
+		want              string
+	}{
+		{"fits", Options{}, "report", "", "pdf", false, "report.pdf"},
+		{"default-255", Options{}, strings.Repeat("a", 300), "", "pdf", false, strings.Repeat("a", 251) + ".pdf"},
+		{"no-limit", Options{MaxLength: -1}, strings.Repeat("a", 300), "", "", false, strings.Repeat("a", 300)},
+		{"keeps-date-prefix", Options{MaxLength: 24}, "2024-01-02_Very_Long_Scanner_Title", "", "pdf", false, "2024-01-02_Very_Long.pdf"},
+		{"date-prefix-whole", Options{MaxLength: 22}, "2024-01-02_A_Very_Long_Title", "", "pdf", true, "2024-01-02_" + dateHash + ".pdf"},
+		{"utf8-boundary", Options{MaxLength: 9}, "ééééé", "", "", false, "éééé"},
+		{"chars", Options{MaxChars: 10}, "ééééééééé", "", "txt", false, "éééééé.txt"},
+		{"trailing-separator", Options{MaxLength: 10}, "abcde_fghij", "", "txt", false, "abcde.txt"},
+		{"keeps-suffix", Options{MaxLength: 12}, "abcdefgh", "_2", "txt", false, "abcdef_2.txt"},
+		{"hash", Options{MaxLength: 20}, "abcdefghijklmnopqrstuvwxyz", "", "txt", true, "abcdefghi_" + hash + ".txt"},
+		{"long-extension", Options{MaxLength: 8}, "a", "_2", "verylongext", false, "a.very_2"},
+		{"cut-to-reserved", Options{MaxLength: 8, Target: TargetWindows}, "CON_abcdefgh", "", "txt", false, "_CON.txt"},
+		{"suffix-to-reserved", Options{MaxLength: 8, Target: TargetWindows}, "COM_abc", "2", "txt", false, "_CO2.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.fitName(tt.base, tt.suffix, tt.ext, tt.hash); got != tt.want {
+				t.Errorf("fitName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestWalkMaxLength tests that collision suffixes stay within the limit.
+func TestWalkMaxLength(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "scanned_document.pdf", "Scanned Document.pdf")
+
+	p, _ := NewPlan([]string{filepath.Join(tmpDir, "Scanned Document.pdf")}, Options{Case: "lower", MaxLength: 16})
+
+	// "scanned_document.pdf" is cut to "scanned_docu.pdf", which is free
+	if got := planNames(p)["Scanned Document.pdf"]; got != "scanned_docu.pdf" {
+		t.Errorf("NewPlan() -> %q, want %q", got, "scanned_docu.pdf")
+	}
+
+	touch(t, tmpDir, "scanned_docu.pdf")
+	p, _ = NewPlan([]string{filepath.Join(tmpDir, "Scanned Document.pdf")}, Options{Case: "lower", MaxLength: 16})
+	if got := planNames(p)["Scanned Document.pdf"]; got != "scanned_do_2.pdf" {
+		t.Errorf("NewPlan() -> %q, want %q", got, "scanned_do_2.pdf")
+	}
+}
+
+// TestCleanNameLengthReserved tests that shortening never leaves a reserved name.
+func TestCleanNameLengthReserved(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"CON_abcdefgh.txt", "_CON.txt"},
+		{"NUL-x.txt", "_NUL.txt"},
+		{"LPT1 report.txt", "_LPT.txt"},
+		{"Console.txt", "Cons.txt"},
+	}
+	for _, tt := range tests {
+		got, err := CleanName(tt.name, tt.name, false, Options{MaxLength: 8, Target: TargetWindows})
+		if err != nil || got != tt.want {
+			t.Errorf("CleanName(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// moves.go
+// ---------
+// Rename sequencing for Cleanfy.
+// A batch of renames within one directory may form chains (a → b, b → c)
+// or cycles (a → b, b → a). sequenceMoves orders the renames so that no
+// destination is still in use, and breaks cycles through a temporary name.
+
+package clean
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// errDestinationExists is returned when a rename target is in use.
+var errDestinationExists = errors.New("destination exists")
+
+// move is a single rename within one directory.
+type move struct {
+	dir      string // Directory containing both names
+	from, to string // Names before and after the rename
+	ref      int    // Index of the Result the move belongs to
+	replace  bool   // Replace an existing destination (ConflictOverwrite)
+	temp     bool   // Destination is a temporary name
+}
+
+// paths returns the full source and destination paths of m.
+func (m move) paths() (string, string) {
+	return filepath.Join(m.dir, m.from), filepath.Join(m.dir, m.to)
+}
+
+// run performs the rename, refusing to replace an existing destination
+// unless m.replace is set.
+func (m move) run() error {
+	from, to := m.paths()
+	if m.replace {
+		return os.Rename(from, to)
+	}
+	return renameNoReplace(from, to)
+}
+
+// linkRename renames from to to without replacing an existing file: the
+// hard link fails if to exists, and only then is from removed.
+// Directories and filesystems without hard links fall back to a checked
+// rename, which leaves a short window for a race.
+func linkRename(from, to string) error {
+	err := os.Link(from, to)
+	if err == nil {
+		return os.Remove(from)
+	}
+	if errors.Is(err, fs.ErrExist) {
+		return errDestinationExists
+	}
+	if _, err := os.Lstat(to); err == nil {
+		return errDestinationExists
+	}
+	return os.Rename(from, to)
+}
+
+// sequenceMoves orders the moves of one directory so that every move runs
+// after the move that vacates its destination. Cycles are broken by first
+// moving one member to a temporary name; that member's final move then
+// runs last in its cycle. With fold set (case-insensitive directories),
+// names are compared case-insensitively and case-only renames
+// (README.MD → readme.md) also go through a temporary name.
+// Otherwise the given order is kept.
+func sequenceMoves(moves []move, fold bool) []move {
+	bySource := make(map[string]int, len(moves))
+	used := make(map[string]bool, 2*len(moves))
+	for i, m := range moves {
+		bySource[nameKey(m.from, fold)] = i
+		used[nameKey(m.from, fold)] = true
+		used[nameKey(m.to, fold)] = true
+	}
+
+	const (
+		pending = iota
+		visiting
+		done
+	)
+	state := make([]int, len(moves))
+	out := make([]move, 0, len(moves))
+
+	// stash moves i to a temporary name first
+	stash := func(i int) {
+		tmp := tempName(moves[i].dir, used)
+		out = append(out, move{dir: moves[i].dir, from: moves[i].from, to: tmp, ref: moves[i].ref, temp: true})
+		moves[i].from = tmp
+	}
+
+	var emit func(i int)
+	emit = func(i int) {
+		switch state[i] {
+		case done:
+			return
+		case visiting:
+			// Cycle: move i out of the way so its predecessor can proceed
+			stash(i)
+			return
+		}
+		state[i] = visiting
+		if j, ok := bySource[nameKey(moves[i].to, fold)]; ok && j != i {
+			emit(j)
+		}
+		if fold && nameKey(moves[i].from, fold) == nameKey(moves[i].to, fold) {
+			stash(i)
+		}
+		out = append(out, moves[i])
+		state[i] = done
+	}
+
+	for i := range moves {
+		emit(i)
+	}
+	return out
+}
+
+// tempCounter makes temporary names unique within the process.
+var tempCounter atomic.Uint64
+
+// tempName returns a name that exists neither in dir nor in used,
+// and adds it to used.
+func tempName(dir string, used map[string]bool) string {
+	for {
+		name := fmt.Sprintf(".cleanfy-%d-%d.tmp", os.Getpid(), tempCounter.Add(1))
+		if used[name] {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+			continue
+		}
+		used[name] = true
+		return name
+	}
+}
+
+// groupByDir splits indices into groups sharing the same directory,
+// keeping the order of first appearance and the order within each group.
+func groupByDir(indices []int, dirOf func(i int) string) [][]int {
+	pos := make(map[string]int)
+	var groups [][]int
+	for _, i := range indices {
+		dir := dirOf(i)
+		g, ok := pos[dir]
+		if !ok {
+			g = len(groups)
+			pos[dir] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
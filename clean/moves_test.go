@@ -0,0 +1,193 @@
+// moves_test.go
+// --------------
+// Unit tests for rename sequencing.
+// Tests cover chains, swaps and cycles, during a run and during rollback.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rotate returns a pipeline renaming each base to the next one in names,
+// the last wrapping around to the first.
+func rotate(names ...string) *Pipeline {
+	return NewPipeline(NewStep("rotate", func(_ *Context, base, ext string) (string, string, error) {
+		for i, n := range names {
+			if base == n {
+				return names[(i+1)%len(names)], ext, nil
+			}
+		}
+		return base, ext, nil
+	}))
+}
+
+// writeNamed creates each file with its own name as content.
+func writeNamed(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkContent fails unless each file holds the expected content.
+func checkContent(t *testing.T, dir string, want map[string]string) {
+	t.Helper()
+	list, _ := os.ReadDir(dir)
+	if len(list) != len(want) {
+		var names []string
+		for _, e := range list {
+			names = append(names, e.Name())
+		}
+		t.Errorf("directory holds %v, want %d entries", names, len(want))
+	}
+	for name, content := range want {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(b) != content {
+			t.Errorf("%q holds %q (%v), want %q", name, b, err, content)
+		}
+	}
+}
+
+// TestSequenceMoves tests the order of chains, cycles and case-only renames.
+func TestSequenceMoves(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves [][2]string
+		fold  bool
+		want  string
+	}{
+		{"independent", [][2]string{{"a", "x"}, {"b", "y"}}, false, "a>x b>y"},
+		{"chain", [][2]string{{"a", "b"}, {"b", "c"}}, false, "b>c a>b"},
+		{"swap", [][2]string{{"a", "b"}, {"b", "a"}}, false, "a>T b>a T>b"},
+		{"cycle", [][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}}, false, "a>T c>a b>c T>b"},
+		{"case-only", [][2]string{{"README", "readme"}}, true, "README>T T>readme"},
+		{"case-sensitive", [][2]string{{"README", "readme"}}, false, "README>readme"},
+		{"folded-chain", [][2]string{{"a", "X"}, {"x", "y"}}, true, "x>y a>X"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var moves []move
+			for i, m := range tt.moves {
+				moves = append(moves, move{dir: t.TempDir(), from: m[0], to: m[1], ref: i})
+			}
+			var got []string
+			for _, m := range sequenceMoves(moves, tt.fold) {
+				from, to := m.from, m.to
+				if strings.HasPrefix(from, ".cleanfy-") {
+					from = "T"
+				}
+				if strings.HasPrefix(to, ".cleanfy-") {
+					to = "T"
+				}
+				got = append(got, from+">"+to)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("sequenceMoves() = %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+// TestWalkSwapAndCycle tests that swaps and cycles end up exactly as
+// planned, and that undoing the run restores every file.
+func TestWalkSwapAndCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "a.txt", "b.txt", "x.txt", "y.txt", "z.txt")
+
+	// a <-> b, and x -> y -> z -> x
+	p := rotate("a", "b")
+	p.Append(rotate("x", "y", "z").Steps()...)
+	journalFile := filepath.Join(t.TempDir(), "journal.jsonl")
+	j := NewJournal(journalFile)
+	results := Walk([]string{tmpDir}, Options{Execute: true, Pipeline: p, Journal: j})
+	j.Close()
+
+	for _, r := range results {
+		if r.HasError() || !r.Renamed || r.AutoRenamed {
+			t.Errorf("Walk() %q -> %q: Renamed = %v, AutoRenamed = %v, Error = %q",
+				r.OldName, r.NewName, r.Renamed, r.AutoRenamed, r.Error)
+		}
+	}
+	checkContent(t, tmpDir, map[string]string{
+		"a.txt": "b.txt", "b.txt": "a.txt",
+		"x.txt": "z.txt", "y.txt": "x.txt", "z.txt": "y.txt",
+	})
+
+	for _, r := range Undo(journalFile, "") {
+		if r.HasError() {
+			t.Errorf("Undo() %q error = %q", r.OldName, r.Error)
+		}
+	}
+	checkContent(t, tmpDir, map[string]string{
+		"a.txt": "a.txt", "b.txt": "b.txt",
+		"x.txt": "x.txt", "y.txt": "y.txt", "z.txt": "z.txt",
+	})
+}
+
+// TestApplyAtomicRollbackSwap tests that rolling back a swap restores both files.
+func TestApplyAtomicRollbackSwap(t *testing.T) {
+	tmpDir := t.TempDir()
+	sub := filepath.Join(tmpDir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeNamed(t, sub, "a.txt", "b.txt")
+	writeNamed(t, tmpDir, "c.txt")
+
+	p := rotate("a", "b")
+	p.Append(NewStep("c", func(_ *Context, base, ext string) (string, string, error) {
+		if base == "c" {
+			return "d", ext, nil
+		}
+		return base, ext, nil
+	}))
+	plan, _ := NewPlan([]string{tmpDir}, Options{Recursive: true, Pipeline: p})
+	// The swap runs first (deepest), then c.txt -> d.txt fails
+	writeNamed(t, tmpDir, "d.txt")
+
+	results := Apply(plan, Options{Atomic: true, OnConflict: ConflictError})
+
+	if !Failed(results) {
+		t.Fatalf("Apply() did not report failure")
+	}
+	checkContent(t, sub, map[string]string{"a.txt": "a.txt", "b.txt": "b.txt"})
+	for _, r := range results {
+		if r.Renamed {
+			t.Errorf("Apply() %q still renamed after rollback", r.OldName)
+		}
+	}
+}
+
+// TestWalkSwapDirectories tests that paths inside swapped directories
+// are reported at their final location.
+func TestWalkSwapDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeNamed(t, filepath.Join(tmpDir, dir), "in_"+dir)
+	}
+
+	results := Walk([]string{tmpDir}, Options{Execute: true, Recursive: true, Pipeline: rotate("a", "b")})
+
+	want := map[string]string{
+		"in_a": filepath.Join(tmpDir, "b", "in_a"),
+		"in_b": filepath.Join(tmpDir, "a", "in_b"),
+	}
+	for _, r := range results {
+		if r.HasError() {
+			t.Errorf("Walk() %q error = %q", r.OldName, r.Error)
+		}
+		if path, ok := want[r.OldName]; ok && r.Path != path {
+			t.Errorf("Walk() %q Path = %q, want %q", r.OldName, r.Path, path)
+		}
+	}
+	checkContent(t, filepath.Join(tmpDir, "b"), map[string]string{"in_a": "in_a"})
+}
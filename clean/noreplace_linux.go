@@ -0,0 +1,32 @@
+// noreplace_linux.go
+// -------------------
+// Race-free renames on Linux via renameat2(RENAME_NOREPLACE).
+// The kernel refuses to replace an existing destination, so there is no
+// gap between checking the destination and renaming.
+
+//go:build linux
+
+package clean
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// renameNoReplace renames from to to, failing with errDestinationExists
+// if to exists. Kernels or filesystems without RENAME_NOREPLACE fall
+// back to linkRename.
+func renameNoReplace(from, to string) error {
+	switch err := unix.Renameat2(unix.AT_FDCWD, from, unix.AT_FDCWD, to, unix.RENAME_NOREPLACE); err {
+	case nil:
+		return nil
+	case unix.EEXIST:
+		return errDestinationExists
+	case unix.ENOSYS, unix.EINVAL:
+		// Not supported by the kernel or the filesystem
+		return linkRename(from, to)
+	default:
+		return &os.LinkError{Op: "rename", Old: from, New: to, Err: err}
+	}
+}
@@ -0,0 +1,14 @@
+// noreplace_other.go
+// -------------------
+// Renames that never replace an existing file on systems without
+// renameat2(RENAME_NOREPLACE).
+
+//go:build !linux
+
+package clean
+
+// renameNoReplace renames from to to, failing with errDestinationExists
+// if to exists.
+func renameNoReplace(from, to string) error {
+	return linkRename(from, to)
+}
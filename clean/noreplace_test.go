@@ -0,0 +1,93 @@
+// noreplace_test.go
+// ------------------
+// Unit tests for renames that never replace an existing file.
+// Tests cover the platform rename, the link fallback and retries under
+// the next free name.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRenameNoReplace tests both rename implementations on files and directories.
+func TestRenameNoReplace(t *testing.T) {
+	impls := map[string]func(from, to string) error{
+		"renameNoReplace": renameNoReplace,
+		"linkRename":      linkRename,
+	}
+	for name, rename := range impls {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeNamed(t, tmpDir, "a", "b")
+			dirs := t.TempDir()
+			if err := os.Mkdir(filepath.Join(dirs, "dir"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			path := func(name string) string { return filepath.Join(tmpDir, name) }
+
+			if err := rename(path("a"), path("b")); err != errDestinationExists {
+				t.Errorf("%s(a, b) error = %v, want %v", name, err, errDestinationExists)
+			}
+			writeNamed(t, dirs, "b")
+			dir := func(name string) string { return filepath.Join(dirs, name) }
+			if err := rename(dir("dir"), dir("b")); err != errDestinationExists {
+				t.Errorf("%s(dir, b) error = %v, want %v", name, err, errDestinationExists)
+			}
+			if err := rename(path("a"), path("c")); err != nil {
+				t.Errorf("%s(a, c) error = %v", name, err)
+			}
+			if err := rename(dir("dir"), dir("dir2")); err != nil {
+				t.Errorf("%s(dir, dir2) error = %v", name, err)
+			}
+			if info, err := os.Stat(dir("dir2")); err != nil || !info.IsDir() {
+				t.Errorf("%s(dir, dir2) left no directory: %v", name, err)
+			}
+			checkContent(t, tmpDir, map[string]string{"b": "b", "c": "a"})
+		})
+	}
+}
+
+// TestRetriesTakenName tests that when executing right after planning
+// (Walk -x), a destination taken in between is neither overwritten nor
+// fatal: the entry moves to the next suffix.
+func TestRetriesTakenName(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "My File.txt")
+
+	opts := Options{Case: "lower"}
+	p, results := newPlan(collect([]string{filepath.Join(tmpDir, "My File.txt")}, false), opts)
+	// Another process drops a file under the planned name
+	writeNamed(t, tmpDir, "my_file.txt", "my_file_2.txt")
+
+	p.apply(results, func(k int) int { return p.Entries[k].index }, opts.retriesTaken(), opts)
+
+	r := results[0]
+	if r.HasError() || !r.Renamed || r.NewName != "my_file_3.txt" || !r.AutoRenamed || r.Suffix != 3 {
+		t.Errorf("apply() = %+v, want rename to my_file_3.txt with suffix 3", r)
+	}
+	checkContent(t, tmpDir, map[string]string{
+		"my_file.txt": "my_file.txt", "my_file_2.txt": "my_file_2.txt", "my_file_3.txt": "My File.txt",
+	})
+}
+
+// TestApplyRefusesTakenName tests that Apply refuses an entry whose
+// destination was taken after planning instead of renaming it to a name
+// that is not in the plan.
+func TestApplyRefusesTakenName(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeNamed(t, tmpDir, "A File.txt")
+
+	plan, _ := NewPlan([]string{tmpDir}, Options{Case: "lower"})
+	writeNamed(t, tmpDir, "a_file.txt")
+
+	results := Apply(plan, Options{})
+
+	r := results[0]
+	if r.Renamed || r.Error != errDestinationExists.Error() {
+		t.Errorf("Apply() = %+v, want refused with %q", r, errDestinationExists)
+	}
+	checkContent(t, tmpDir, map[string]string{"A File.txt": "A File.txt", "a_file.txt": "a_file.txt"})
+}
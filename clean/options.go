@@ -0,0 +1,118 @@
+// options.go
+// -----------
+// Defines the Options struct that configures the Cleanfy pipeline.
+// Options are passed explicitly to CleanName, ProcessOne and Walk so that
+// several configurations can be used concurrently in one process.
+
+package clean
+
+import "fmt"
+
+// DefaultDateFormat is the date prefix layout used when Options.DateFormat is empty.
+const DefaultDateFormat = "2006-01-02"
+
+// Options configures name cleaning and renaming.
+// The zero value is valid: it cleans names without case or date changes
+// and only previews renames.
+type Options struct {
+	Case         string    // Case transform: "", "none", "lower", "upper" or "title"
+	DateMode     string    // Date prefix: "" (none), "mtime" or "now"
+	DateFormat   string    // Go time layout for the date prefix (default: DefaultDateFormat)
+	Execute      bool      // Perform renames on disk; when false only a preview is computed
+	Dotfiles     bool      // Include hidden files (starting with '.')
+	Recursive    bool      // Descend into subdirectories in Walk
+	Explain      bool      // Record every pipeline step in Result.Steps
+	Journal      *Journal  // Journal recording executed renames (optional)
+	Atomic       bool      // Roll back all renames of a run if any entry fails (never overwrites)
+	Pipeline     *Pipeline // Steps run by CleanName (default: DefaultPipeline)
+	OnConflict   string    // Collision strategy: "" (suffix) or one of the Conflict* constants
+	SuffixFormat string    // Collision suffix layout with one %d verb (default: DefaultSuffixFormat)
+	SuffixStart  int       // First collision suffix number (default: DefaultSuffixStart)
+	FSCase       string    // Filesystem case handling: "" (auto) or one of the FSCase* constants
+	MaxLength    int       // Name length limit in bytes (default: DefaultMaxLength; negative: no limit)
+	MaxChars     int       // Name length limit in characters (0: no limit)
+	TruncHash    bool      // End shortened names in a short hash of the full name
+	MaxPath      int       // Resulting path length limit in bytes (default: the target's; negative: no limit)
+	Target       string    // Destination filesystem rules: "" (default) or one of the Target* constants
+	ShortNames   string    // 8.3 short names: "" (off) or one of the Short* constants
+	CompoundExts []string  // Multi-part extensions kept as one unit, e.g. "tar.gz" (nil: DefaultCompoundExts)
+	NormalizeExt bool      // Canonicalize extensions: lower case, mapped by ExtMap and DefaultExtMap
+	FixExt       string    // Extension fix from the file content: "" (off) or one of the FixExt* constants
+	OnDangerous  string    // Handling of disguised executables: "" (warn) or one of the Dangerous* constants
+
+	// ExtMap overrides DefaultExtMap for NormalizeExt, e.g. "tif": "tiff"
+	// to prefer the long form. Extensions are given without the dot.
+	ExtMap map[string]string
+
+	// Ask chooses the strategy for one conflict when OnConflict is
+	// ConflictAsk. It receives the source path and the clashing name and
+	// returns one of the other Conflict* constants; anything else skips
+	// the entry.
+	Ask func(path, name string) string
+
+	// MapName receives the original and final path of every rename of
+	// Walk: planned ones in a dry run, completed ones with Execute.
+	// Children come before their parents. Used to keep track of short
+	// names (optional).
+	MapName func(original, final string)
+}
+
+// Validate checks that all option values are known.
+func (o Options) Validate() error {
+	switch o.Case {
+	case "", "none", "lower", "upper", "title":
+	default:
+		return fmt.Errorf("invalid case %q: use one of none | lower | upper | title", o.Case)
+	}
+	switch o.DateMode {
+	case "", "mtime", "now":
+	default:
+		return fmt.Errorf("invalid date mode %q: use one of mtime | now", o.DateMode)
+	}
+	switch o.FSCase {
+	case "", FSCaseAuto, FSCaseSensitive, FSCaseInsensitive:
+	default:
+		return fmt.Errorf("invalid fs-case %q: use one of auto | sensitive | insensitive", o.FSCase)
+	}
+	if o.MaxLength > 0 && o.MaxLength < minLength {
+		return fmt.Errorf("invalid max length %d: must be at least %d bytes", o.MaxLength, minLength)
+	}
+	if o.MaxChars < 0 || o.MaxChars > 0 && o.MaxChars < minLength {
+		return fmt.Errorf("invalid max chars %d: must be 0 (no limit) or at least %d", o.MaxChars, minLength)
+	}
+	if err := o.validateTarget(); err != nil {
+		return err
+	}
+	if err := o.validateShort(); err != nil {
+		return err
+	}
+	if err := o.validateExts(); err != nil {
+		return err
+	}
+	if err := o.validateFixExt(); err != nil {
+		return err
+	}
+	if err := o.validateDangerous(); err != nil {
+		return err
+	}
+	return o.validateConflict()
+}
+
+// defaultPipeline is shared by all Options without a custom Pipeline.
+var defaultPipeline = DefaultPipeline()
+
+// pipeline returns the configured pipeline or the default one.
+func (o Options) pipeline() *Pipeline {
+	if o.Pipeline == nil {
+		return defaultPipeline
+	}
+	return o.Pipeline
+}
+
+// dateFormat returns the configured date layout or DefaultDateFormat.
+func (o Options) dateFormat() string {
+	if o.DateFormat == "" {
+		return DefaultDateFormat
+	}
+	return o.DateFormat
+}
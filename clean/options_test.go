@@ -0,0 +1,34 @@
+// options_test.go
+// ----------------
+// Unit tests for Options validation.
+
+package clean
+
+import "testing"
+
+// TestOptionsValidate tests that only known option values are accepted.
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"zero-value", Options{}, false},
+		{"case-lower", Options{Case: "lower"}, false},
+		{"case-title", Options{Case: "title"}, false},
+		{"case-invalid", Options{Case: "camel"}, true},
+		{"date-mtime", Options{DateMode: "mtime"}, false},
+		{"date-invalid", Options{DateMode: "yesterday"}, true},
+		{"target-windows", Options{Target: TargetWindows}, false},
+		{"target-invalid", Options{Target: "ntfs"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
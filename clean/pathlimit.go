@@ -0,0 +1,145 @@
+// pathlimit.go
+// -------------
+// Full-path length limits for Cleanfy (--max-path).
+// Windows refuses paths longer than MAX_PATH (260) and many archive tools
+// choke on long member paths. The resulting path of every entry is
+// measured with its parent directories renamed too, and the longest names
+// on the path are shortened until it fits.
+
+package clean
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// maxPath returns the path length limit in bytes, or 0 for none.
+func (o Options) maxPath() int {
+	switch {
+	case o.MaxPath < 0:
+		return 0
+	case o.MaxPath == 0:
+		return o.target().maxPath
+	}
+	return o.MaxPath
+}
+
+// limitPaths shortens the names proposed in entries (indexed like items,
+// nil for entries that cannot be renamed) until the resulting path of
+// every item is at most opts.maxPath() bytes. Paths are measured as given
+// to the walk, so a relative target is measured relative to it.
+// The longest name on a path is shortened first, the deepest one on ties,
+// and only down to the next-longest one before the path is measured
+// again, so the names on a path end up with similar lengths.
+// Items whose path cannot be shortened enough get an error in results.
+// It reports whether any name was shortened.
+func limitPaths(items []walkItem, entries []*PlanEntry, results []Result, opts Options) bool {
+	index := make(map[string]int, len(items))
+	for i, it := range items {
+		if it.err == nil && results[i].Error == "" {
+			index[it.path] = i
+		}
+	}
+	name := func(j int) string {
+		if e := entries[j]; e != nil && !e.dropped() {
+			return e.NewName
+		}
+		return filepath.Base(items[j].path)
+	}
+
+	shortened := false
+	stuck := make(map[int]bool) // Entries that could not be cut further
+	for _, i := range deepestFirst(items) {
+		if _, ok := index[items[i].path]; !ok {
+			continue
+		}
+		// Items on the path, innermost first, and the path above them
+		var chain []int
+		root := items[i].path
+		for {
+			j, ok := index[root]
+			if !ok {
+				break
+			}
+			chain = append(chain, j)
+			parent := filepath.Dir(root)
+			if parent == root {
+				root = ""
+				break
+			}
+			root = parent
+		}
+
+		reason := "" // The path before any of its names were shortened
+		for {
+			parts := []string{root}
+			for k := len(chain) - 1; k >= 0; k-- {
+				parts = append(parts, name(chain[k]))
+			}
+			full := filepath.Join(parts...)
+			over := len(full) - opts.maxPath()
+			if over <= 0 {
+				break
+			}
+			if reason == "" {
+				reason = fmt.Sprintf("path %s is %d bytes, max-path %d", full, len(full), opts.maxPath())
+			}
+
+			// The longest name and the next-longest one
+			pick, next := -1, 0
+			for _, j := range chain {
+				e := entries[j]
+				if e == nil || e.dropped() || stuck[j] || len(e.NewName) <= minLength {
+					continue
+				}
+				switch {
+				case pick < 0 || len(e.NewName) > len(entries[pick].NewName):
+					if pick >= 0 {
+						next = len(entries[pick].NewName)
+					}
+					pick = j
+				case len(e.NewName) > next:
+					next = len(e.NewName)
+				}
+			}
+			if pick < 0 {
+				results[i].Error = fmt.Sprintf("path of %d bytes exceeds max-path %d", len(full), opts.maxPath())
+				break
+			}
+			e := entries[pick]
+			if e.Shortened == "" {
+				e.Shortened = reason
+			}
+			// Cut only down to the next-longest name, so names on the path
+			// are evened out step by step; on ties, by one byte
+			size := len(e.NewName)
+			limit := max(minLength, next, size-over)
+			if limit >= size {
+				limit = size - 1
+			}
+			e.shorten(limit, opts)
+			if len(e.NewName) >= size {
+				stuck[pick] = true
+			}
+			shortened = true
+		}
+	}
+	return shortened
+}
+
+// shorten cuts the cleaned name of e to at most limit bytes and keeps
+// that limit for the collision suffix, which resolving adds again.
+func (e *PlanEntry) shorten(limit int, opts Options) {
+	e.maxLength = limit
+	base, ext := opts.splitName(e.IntendedName, e.IsDir)
+	e.IntendedName = e.limits(opts).fitName(base, "", ext, opts.TruncHash)
+	e.NewName = e.IntendedName
+}
+
+// limits returns opts with the name length limit of e applied.
+func (e *PlanEntry) limits(opts Options) Options {
+	if e.maxLength > 0 {
+		opts.MaxLength = e.maxLength
+	}
+	return opts
+}
@@ -0,0 +1,186 @@
+// pathlimit_test.go
+// ------------------
+// Unit tests for full-path length limits (--max-path).
+// Tests cover which names are shortened and by how much, collisions among
+// shortened names and paths that cannot be shortened enough.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMaxPath tests that the longest, then deepest, names of an over-long
+// path are shortened, down to the next-longest name at a time.
+func TestMaxPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		dir       string
+		files     []string
+		maxPath   int
+		want      map[string]string // Old name -> new name
+		shortened []string          // Old names reported as shortened
+	}{
+		{
+			name:      "fits",
+			dir:       "Quarterly Reports",
+			files:     []string{"Final Budget Overview.xlsx"},
+			maxPath:   44,
+			want:      map[string]string{"Quarterly Reports": "quarterly_reports", "Final Budget Overview.xlsx": "final_budget_overview.xlsx"},
+			shortened: nil,
+		},
+		{
+			name:      "evened-out",
+			dir:       "Quarterly Reports",
+			files:     []string{"Final Budget Overview.xlsx"},
+			maxPath:   30,
+			want:      map[string]string{"Quarterly Reports": "quarterly_repor", "Final Budget Overview.xlsx": "final_bud.xlsx"},
+			shortened: []string{"Quarterly Reports", "Final Budget Overview.xlsx"},
+		},
+		{
+			name:      "dir-longest",
+			dir:       "Quarterly Financial Reports",
+			files:     []string{"Budget.xlsx"},
+			maxPath:   30,
+			want:      map[string]string{"Quarterly Financial Reports": "quarterly_financia", "Budget.xlsx": "budget.xlsx"},
+			shortened: []string{"Quarterly Financial Reports"},
+		},
+		{
+			name:      "collision-within-limit",
+			dir:       "Quarterly Reports",
+			files:     []string{"Final Budget Overview.xlsx", "Final Budget Outline.xlsx"},
+			maxPath:   30,
+			want:      map[string]string{"Final Budget Outline.xlsx": "final_bud.xlsx", "Final Budget Overview.xlsx": "final_b_2.xlsx"},
+			shortened: []string{"Quarterly Reports", "Final Budget Outline.xlsx", "Final Budget Overview.xlsx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if err := os.Mkdir(tt.dir, 0755); err != nil {
+				t.Fatalf("failed to create test dir: %v", err)
+			}
+			touch(t, tt.dir, tt.files...)
+
+			_, results := NewPlan([]string{tt.dir}, Options{Case: "lower", Recursive: true, MaxPath: tt.maxPath})
+			byName := make(map[string]Result)
+			for _, r := range results {
+				if r.Error != "" {
+					t.Errorf("%s: unexpected error %q", r.Path, r.Error)
+				}
+				byName[r.OldName] = r
+			}
+			for old, want := range tt.want {
+				if got := byName[old].NewName; got != want {
+					t.Errorf("%q -> %q, want %q", old, got, want)
+				}
+			}
+			var shortened []string
+			for _, r := range results {
+				if r.Shortened != "" {
+					shortened = append(shortened, r.OldName)
+				}
+			}
+			if strings.Join(shortened, "|") != strings.Join(tt.shortened, "|") {
+				t.Errorf("shortened = %q, want %q", shortened, tt.shortened)
+			}
+		})
+	}
+}
+
+// TestMaxPathSiblings tests that files sharing over-long parent
+// directories are shortened alike, whichever comes first in the walk.
+func TestMaxPathSiblings(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dir := filepath.Join("Quarterly Financial Reports 024", "Quarterly Financial Reports 025")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create test dir: %v", err)
+	}
+	touch(t, dir, "Some Long File Name Inside Too.txt", "Some Long File Name Inside.txt")
+
+	_, results := NewPlan([]string{"Quarterly Financial Reports 024"}, Options{Recursive: true, MaxPath: 60})
+	names := make(map[string]string)
+	for _, r := range results {
+		if r.Error != "" {
+			t.Errorf("%s: unexpected error %q", r.Path, r.Error)
+		}
+		names[r.OldName] = r.NewName
+	}
+	for _, file := range []string{"Some Long File Name Inside Too.txt", "Some Long File Name Inside.txt"} {
+		path := filepath.Join(names["Quarterly Financial Reports 024"], names["Quarterly Financial Reports 025"], names[file])
+		if len(path) > 60 {
+			t.Errorf("%q -> %q: path of %d bytes, want at most 60", file, path, len(path))
+		}
+	}
+	long, short := len(names["Some Long File Name Inside Too.txt"]), len(names["Some Long File Name Inside.txt"])
+	if long-short > 2 || short-long > 2 {
+		t.Errorf("sibling names %q and %q, want comparable lengths", names["Some Long File Name Inside Too.txt"], names["Some Long File Name Inside.txt"])
+	}
+}
+
+// TestMaxPathTooShort tests that a path which cannot be shortened enough is reported and left alone.
+func TestMaxPathTooShort(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("Quarterly Reports", 0755); err != nil {
+		t.Fatalf("failed to create test dir: %v", err)
+	}
+	touch(t, "Quarterly Reports", "Final Budget Overview.xlsx")
+
+	p, results := NewPlan([]string{"Quarterly Reports"}, Options{Case: "lower", Recursive: true, MaxPath: 12})
+	for _, r := range results {
+		switch r.OldName {
+		case "Final Budget Overview.xlsx":
+			if !strings.Contains(r.Error, "exceeds max-path 12") {
+				t.Errorf("Error = %q, want max-path error", r.Error)
+			}
+		case "Quarterly Reports":
+			if r.NewName != "quarterl" || r.Shortened == "" {
+				t.Errorf("dir -> %q (shortened %q), want %q shortened", r.NewName, r.Shortened, "quarterl")
+			}
+		}
+	}
+	if got := planNames(p); len(got) != 1 {
+		t.Errorf("plan renames %v, want only the directory", got)
+	}
+}
+
+// TestMaxPathSuffix tests that a collision suffix pushing a path over the
+// limit is kept when the name is shortened.
+func TestMaxPathSuffix(t *testing.T) {
+	tests := []struct {
+		suffix     string
+		maxPath    int
+		want       string
+		wantSuffix int
+	}{
+		{"", 21, "long_name_her_2.txt", 2},
+		{" (%d)", 23, "long_name_her (2).txt", 2},
+		{"-%03d", 22, "long_name_he-002.txt", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if err := os.Mkdir("d", 0755); err != nil {
+				t.Fatalf("failed to create test dir: %v", err)
+			}
+			touch(t, "d", "long_name_here.txt", "Long Name Here.txt")
+
+			opts := Options{Case: "lower", Recursive: true, MaxPath: tt.maxPath, SuffixFormat: tt.suffix}
+			_, results := NewPlan([]string{"d"}, opts)
+			for _, r := range results {
+				if r.OldName != "Long Name Here.txt" {
+					continue
+				}
+				if r.Error != "" || r.NewName != tt.want || !r.AutoRenamed || r.Suffix != tt.wantSuffix || r.Shortened == "" {
+					t.Errorf("-> %q (auto-renamed %v, suffix %d, shortened %q, error %q), want %q with suffix %d",
+						r.NewName, r.AutoRenamed, r.Suffix, r.Shortened, r.Error, tt.want, tt.wantSuffix)
+				}
+			}
+		})
+	}
+}
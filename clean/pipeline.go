@@ -0,0 +1,233 @@
+// pipeline.go
+// ------------
+// Pluggable transformation pipeline for Cleanfy.
+// A Pipeline is an ordered list of named Steps, each transforming the
+// base name and extension of an entry. Steps can be inserted, removed,
+// reordered and listed, so custom rules can run alongside the built-in ones.
+
+package clean
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Context describes the entry being cleaned. It is passed to every Step.
+// Steps record what they found about the entry in the remaining fields,
+// which end up in its Result.
+type Context struct {
+	Path   string  // Full path of the entry on disk
+	Name   string  // Original name of the entry
+	IsDir  bool    // True if the entry is a directory
+	Hidden bool    // True if the name keeps a leading dot, which is not part of the base
+	Opts   Options // Options the pipeline runs with
+
+	Detected    string // Content type found by the sniff step, as its canonical extension
+	ExtMismatch bool   // True if the extension does not match Detected and was kept
+	Dangerous   string // Why the name looks like a disguised executable, set by the security step
+}
+
+// Step is one named transformation of a pipeline.
+// Apply receives the current base name and extension (without the dot)
+// and returns the transformed pair.
+type Step interface {
+	Name() string
+	Apply(ctx *Context, base, ext string) (string, string, error)
+}
+
+// StepFunc is the transform function of a step created with NewStep.
+type StepFunc func(ctx *Context, base, ext string) (string, string, error)
+
+type funcStep struct {
+	name string
+	fn   StepFunc
+}
+
+func (s funcStep) Name() string { return s.name }
+
+func (s funcStep) Apply(ctx *Context, base, ext string) (string, string, error) {
+	return s.fn(ctx, base, ext)
+}
+
+// NewStep returns a Step with the given name that runs fn.
+func NewStep(name string, fn StepFunc) Step {
+	return funcStep{name: name, fn: fn}
+}
+
+// Pipeline is an ordered list of steps applied to a split filename.
+// Modifying a pipeline is not safe for concurrent use, but running
+// an unchanged pipeline from several goroutines is.
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline returns a pipeline running steps in the given order.
+func NewPipeline(steps ...Step) *Pipeline {
+	return &Pipeline{steps: append([]Step(nil), steps...)}
+}
+
+// Steps returns a copy of the pipeline's steps in execution order.
+func (p *Pipeline) Steps() []Step {
+	return append([]Step(nil), p.steps...)
+}
+
+// Names returns the names of the pipeline's steps in execution order.
+func (p *Pipeline) Names() []string {
+	names := make([]string, len(p.steps))
+	for i, s := range p.steps {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// Index returns the position of the step called name, or -1.
+func (p *Pipeline) Index(name string) int {
+	for i, s := range p.steps {
+		if s.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Append adds steps at the end of the pipeline.
+func (p *Pipeline) Append(steps ...Step) *Pipeline {
+	p.steps = append(p.steps, steps...)
+	return p
+}
+
+// InsertBefore inserts s before the step called name.
+func (p *Pipeline) InsertBefore(name string, s Step) error {
+	i := p.Index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown step %q", name)
+	}
+	p.insert(i, s)
+	return nil
+}
+
+// InsertAfter inserts s after the step called name.
+func (p *Pipeline) InsertAfter(name string, s Step) error {
+	i := p.Index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown step %q", name)
+	}
+	p.insert(i+1, s)
+	return nil
+}
+
+// Replace substitutes the step called name with s.
+func (p *Pipeline) Replace(name string, s Step) error {
+	i := p.Index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown step %q", name)
+	}
+	p.steps[i] = s
+	return nil
+}
+
+// Remove deletes the step called name.
+func (p *Pipeline) Remove(name string) error {
+	i := p.Index(name)
+	if i < 0 {
+		return fmt.Errorf("unknown step %q", name)
+	}
+	p.steps = append(p.steps[:i], p.steps[i+1:]...)
+	return nil
+}
+
+// Reorder rearranges the pipeline to run the named steps in the given order.
+// names must list every step exactly once.
+func (p *Pipeline) Reorder(names ...string) error {
+	if len(names) != len(p.steps) {
+		return fmt.Errorf("reorder needs %d step names, got %d", len(p.steps), len(names))
+	}
+	steps := make([]Step, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		i := p.Index(name)
+		if i < 0 {
+			return fmt.Errorf("unknown step %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate step %q", name)
+		}
+		seen[name] = true
+		steps = append(steps, p.steps[i])
+	}
+	p.steps = steps
+	return nil
+}
+
+func (p *Pipeline) insert(i int, s Step) {
+	p.steps = append(p.steps, nil)
+	copy(p.steps[i+1:], p.steps[i:])
+	p.steps[i] = s
+}
+
+// StepSplit is the name of the trace entry recorded for the extension split
+// that precedes the pipeline steps.
+const StepSplit = "split"
+
+// Trace records the intermediate name after one pipeline step.
+type Trace struct {
+	Step string `json:"step"`          // Step name (StepSplit for the extension split)
+	Base string `json:"base"`          // Base name after the step
+	Ext  string `json:"ext,omitempty"` // Extension (without dot) after the step
+	Name string `json:"name"`          // Joined name after the step
+}
+
+// Clean runs the pipeline on name and returns the resulting name.
+// The name is split into base and extension first and joined again
+// after the last step. Leading dots of hidden names are set aside before
+// the split and one dot is restored at the end, so ".My Config" stays
+// hidden and ".bashrc" has no extension.
+func (p *Pipeline) Clean(fullPath, name string, isDir bool, opts Options) (string, error) {
+	return p.run(&Context{Path: fullPath, Name: name, IsDir: isDir, Opts: opts}, nil)
+}
+
+// Explain is like Clean but also returns the intermediate result
+// after the extension split and after every step.
+func (p *Pipeline) Explain(fullPath, name string, isDir bool, opts Options) (string, []Trace, error) {
+	var trace []Trace
+	newName, err := p.run(&Context{Path: fullPath, Name: name, IsDir: isDir, Opts: opts}, &trace)
+	return newName, trace, err
+}
+
+// run applies all steps to the entry of ctx, appending to trace when it
+// is not nil.
+func (p *Pipeline) run(ctx *Context, trace *[]Trace) (string, error) {
+	name, isDir, opts := ctx.Name, ctx.IsDir, ctx.Opts
+	rest := strings.TrimLeft(name, ".")
+	if ctx.Hidden = rest != name && rest != "" && !opts.singleDot(); ctx.Hidden {
+		name = rest
+	}
+	base, ext := opts.splitName(name, isDir)
+	joined := func() string {
+		if ctx.Hidden {
+			return "." + joinName(base, ext)
+		}
+		return joinName(base, ext)
+	}
+	record := func(step string) {
+		if trace != nil {
+			*trace = append(*trace, Trace{Step: step, Base: base, Ext: ext, Name: joined()})
+		}
+	}
+	record(StepSplit)
+
+	var err error
+	for _, s := range p.steps {
+		base, ext, err = s.Apply(ctx, base, ext)
+		if err != nil {
+			return ctx.Name, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+		record(s.Name())
+	}
+
+	if base == "" && ext == "" {
+		return ctx.Name, errors.New("empty result name")
+	}
+	return joined(), nil
+}
@@ -0,0 +1,119 @@
+// pipeline_test.go
+// -----------------
+// Unit tests for the pluggable pipeline.
+// Tests cover step introspection, insertion, reordering and custom steps.
+
+package clean
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// TestDefaultPipelineNames tests the order of the built-in steps.
+func TestDefaultPipelineNames(t *testing.T) {
+	want := []string{StepASCII, StepPosix, StepCase, StepSniff, StepExt, StepSecurity, StepDate, StepReserved, StepLength}
+	if got := DefaultPipeline().Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultPipeline().Names() = %v, want %v", got, want)
+	}
+}
+
+// TestPipelineCustomStep tests inserting a custom step between ASCII folding and POSIX filtering.
+func TestPipelineCustomStep(t *testing.T) {
+	projectCode := regexp.MustCompile(`^PRJ-\d+ `)
+	strip := NewStep("strip-project", func(_ *Context, base, ext string) (string, string, error) {
+		return projectCode.ReplaceAllString(base, ""), ext, nil
+	})
+
+	p := DefaultPipeline()
+	if err := p.InsertAfter(StepASCII, strip); err != nil {
+		t.Fatalf("InsertAfter() error = %v", err)
+	}
+	want := []string{StepASCII, "strip-project", StepPosix, StepCase, StepSniff, StepExt, StepSecurity, StepDate, StepReserved, StepLength}
+	if got := p.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	opts := Options{Case: "lower", Pipeline: p}
+	got, err := CleanName("/tmp/x", "PRJ-42 Budget Plan.xlsx", false, opts)
+	if err != nil {
+		t.Fatalf("CleanName() error = %v", err)
+	}
+	if got != "budget_plan.xlsx" {
+		t.Errorf("CleanName() = %q, want %q", got, "budget_plan.xlsx")
+	}
+
+	// Without the step the code stays in the name
+	got, _ = CleanName("/tmp/x", "PRJ-42 Budget Plan.xlsx", false, Options{Case: "lower"})
+	if got != "prj-42_budget_plan.xlsx" {
+		t.Errorf("CleanName() default = %q, want %q", got, "prj-42_budget_plan.xlsx")
+	}
+}
+
+// TestPipelineEdit tests removing, replacing and reordering steps.
+func TestPipelineEdit(t *testing.T) {
+	p := DefaultPipeline()
+
+	if err := p.Remove(StepDate); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if p.Index(StepDate) != -1 {
+		t.Errorf("Index(%q) after Remove = %d, want -1", StepDate, p.Index(StepDate))
+	}
+	if err := p.Remove("missing"); err == nil {
+		t.Errorf("Remove(missing) error = nil, want error")
+	}
+
+	if err := p.Reorder(StepASCII, StepCase, StepPosix, StepSniff, StepExt, StepSecurity, StepReserved, StepLength); err != nil {
+		t.Fatalf("Reorder() error = %v", err)
+	}
+	want := []string{StepASCII, StepCase, StepPosix, StepSniff, StepExt, StepSecurity, StepReserved, StepLength}
+	if got := p.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	if err := p.Reorder(StepASCII, StepASCII, StepPosix, StepSniff, StepExt, StepSecurity, StepReserved, StepLength); err == nil {
+		t.Errorf("Reorder() with duplicate error = nil, want error")
+	}
+
+	upper := NewStep("upper", func(_ *Context, base, ext string) (string, string, error) {
+		return "X" + base, ext, nil
+	})
+	if err := p.Replace(StepCase, upper); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	got, err := p.Clean("/tmp/x", "name.txt", false, Options{})
+	if err != nil {
+		t.Fatalf("Clean() error = %v", err)
+	}
+	if got != "Xname.txt" {
+		t.Errorf("Clean() = %q, want %q", got, "Xname.txt")
+	}
+}
+
+// TestExplainName tests that every step's intermediate result is recorded.
+func TestExplainName(t *testing.T) {
+	got, trace, err := ExplainName("/tmp/x", "Café Menu.PDF", false, Options{Case: "lower"})
+	if err != nil {
+		t.Fatalf("ExplainName() error = %v", err)
+	}
+	if got != "cafe_menu.pdf" {
+		t.Errorf("ExplainName() = %q, want %q", got, "cafe_menu.pdf")
+	}
+
+	want := []Trace{
+		{Step: StepSplit, Base: "Café Menu", Ext: "PDF", Name: "Café Menu.PDF"},
+		{Step: StepASCII, Base: "Cafe Menu", Ext: "PDF", Name: "Cafe Menu.PDF"},
+		{Step: StepPosix, Base: "Cafe_Menu", Ext: "PDF", Name: "Cafe_Menu.PDF"},
+		{Step: StepCase, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepSniff, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepExt, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepSecurity, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepDate, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepReserved, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+		{Step: StepLength, Base: "cafe_menu", Ext: "pdf", Name: "cafe_menu.pdf"},
+	}
+	if !reflect.DeepEqual(trace, want) {
+		t.Errorf("ExplainName() trace = %+v, want %+v", trace, want)
+	}
+}
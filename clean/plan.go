@@ -0,0 +1,372 @@
+// plan.go
+// --------
+// Plan-then-apply workflow for Cleanfy.
+// NewPlan computes every proposed rename (with collisions already resolved)
+// and a fingerprint of each source. Apply executes exactly that plan and
+// refuses entries whose source changed or whose destination was taken
+// since planning.
+
+package clean
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PlanVersion is the version of the plan file format written by Plan.WriteFile.
+const PlanVersion = 1
+
+// Plan is a reviewable list of renames produced by NewPlan.
+type Plan struct {
+	Version int         `json:"version"` // Plan file format version (PlanVersion)
+	Created time.Time   `json:"created"` // Time the plan was made
+	Entries []PlanEntry `json:"entries"` // Renames in execution order
+}
+
+// PlanEntry is one proposed rename.
+type PlanEntry struct {
+	Path         string      `json:"path"`                // Path of the source when the plan was made
+	OldName      string      `json:"old_name"`            // Original name
+	IntendedName string      `json:"intended_name"`       // Cleaned name, before collision handling
+	NewName      string      `json:"new_name"`            // Final name, including any collision suffix
+	IsDir        bool        `json:"is_dir"`              // True if the entry is a directory
+	AutoRenamed  bool        `json:"auto_renamed"`        // True if a suffix was added to avoid a conflict
+	Suffix       int         `json:"suffix,omitempty"`    // Number of the collision suffix, if one was added
+	Conflict     string      `json:"conflict,omitempty"`  // Collision strategy applied if the cleaned name was taken
+	Shortened    string      `json:"shortened,omitempty"` // Why the name was shortened to fit Options.MaxPath
+	Fingerprint  Fingerprint `json:"fingerprint"`         // Identity of the source when the plan was made
+
+	index     int    // Index of the preview Result in NewPlan's results
+	strategy  string // Conflict strategy chosen for the entry, once asked
+	maxLength int    // Name length limit set by Options.MaxPath (0: none)
+}
+
+// Fingerprint identifies a file at planning time.
+// Device and inode are zero on systems that do not provide them.
+type Fingerprint struct {
+	Dev     uint64 `json:"dev"`   // Device number
+	Ino     uint64 `json:"ino"`   // Inode number
+	Size    int64  `json:"size"`  // Size in bytes
+	ModTime int64  `json:"mtime"` // Modification time (Unix nanoseconds)
+}
+
+// fingerprintOf returns the fingerprint of info.
+func fingerprintOf(info os.FileInfo) Fingerprint {
+	dev, ino := fileID(info)
+	return Fingerprint{Dev: dev, Ino: ino, Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+}
+
+// matches reports whether info still describes the fingerprinted file.
+// Size and mtime are not compared for directories, since renaming
+// their children changes them.
+func (f Fingerprint) matches(info os.FileInfo) bool {
+	g := fingerprintOf(info)
+	if f.Dev != g.Dev || f.Ino != g.Ino {
+		return false
+	}
+	if info.IsDir() {
+		return true
+	}
+	return f.Size == g.Size && f.ModTime == g.ModTime
+}
+
+// NewPlan walks targets like Walk and returns the plan of all renames it
+// would perform, along with a preview Result for every visited entry
+// (in walk order). Plan entries are ordered deepest-first, so directories
+// are renamed after their contents.
+// Collisions are resolved per directory for the whole batch: suffixes are
+// assigned in order of original name, so identical trees always produce
+// identical plans. opts.Execute is ignored; nothing is renamed.
+func NewPlan(targets []string, opts Options) (*Plan, []Result) {
+	return newPlan(collect(targets, opts.Recursive), opts)
+}
+
+// newPlan plans the renames of items. Each entry remembers the index of
+// its preview Result.
+func newPlan(items []walkItem, opts Options) (*Plan, []Result) {
+	results := make([]Result, len(items))
+	entries := make([]*PlanEntry, len(items))
+	order := deepestFirst(items)
+	for _, i := range order {
+		it := items[i]
+		if it.err != nil {
+			results[i] = Result{Path: it.path, Error: it.err.Error()}
+			continue
+		}
+		r := propose(it.path, it.info, opts)
+		results[i] = r
+		if r.IntendedName == "" {
+			continue // Skipped or failed: the entry keeps its name
+		}
+
+		linfo, err := os.Lstat(it.path)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		entries[i] = &PlanEntry{
+			Path:         it.path,
+			OldName:      r.OldName,
+			IntendedName: r.NewName,
+			NewName:      r.NewName,
+			IsDir:        r.IsDir,
+			Fingerprint:  fingerprintOf(linfo),
+			index:        i,
+		}
+	}
+
+	// Collisions are resolved again whenever a collision suffix pushed a
+	// path over opts.MaxPath and names had to be shortened once more
+	var b batch
+	queued := make([]bool, len(items))
+	var resolved []*PlanEntry
+	for round := 0; ; round++ {
+		shortened := opts.maxPath() > 0 && limitPaths(items, entries, results, opts)
+		if round > 0 && !shortened {
+			break
+		}
+		for _, i := range order {
+			if e := entries[i]; e != nil && !queued[i] && e.IntendedName != e.OldName {
+				b.add(e)
+				queued[i] = true
+			}
+		}
+		resolved = b.resolve(opts)
+	}
+
+	p := &Plan{Version: PlanVersion, Created: time.Now()}
+	for _, e := range resolved {
+		r := &results[e.index]
+		if r.Error != "" {
+			continue // Path too long even when shortened
+		}
+		r.IntendedName, r.NewName, r.Shortened = e.IntendedName, e.NewName, e.Shortened
+		r.AutoRenamed, r.Suffix, r.Conflict = e.AutoRenamed, e.Suffix, e.Conflict
+		switch e.Conflict {
+		case ConflictSkip:
+			r.WasSkipped = true
+		case ConflictError:
+			r.Error = errDestinationExists.Error()
+		}
+		if !e.dropped() {
+			p.Entries = append(p.Entries, *e)
+		}
+	}
+	return p, results
+}
+
+// Apply executes the renames of p in order. Paths of entries inside a
+// directory renamed later in the plan are reported at their final location.
+// An entry is refused if its source no longer matches the fingerprint
+// taken at planning time. Within a directory, renames are sequenced so
+// that swaps and longer cycles (a → b, b → a) go through a temporary name
+// and end up exactly as planned.
+// Renames never replace an existing file unless planned with
+// ConflictOverwrite. An entry whose destination was taken since planning
+// is refused rather than given a name that is not in the plan.
+// With opts.Atomic, a plan that overwrites files is refused as a whole.
+// Only opts.Journal, opts.Atomic, opts.FSCase and the collision options
+// are used; the naming options were already applied when the plan was made.
+func Apply(p *Plan, opts Options) []Result {
+	results := make([]Result, len(p.Entries))
+	for k, e := range p.Entries {
+		results[k] = e.preview()
+	}
+	if opts.Atomic && p.overwrites() {
+		// A rollback cannot bring back an overwritten file
+		for k, e := range p.Entries {
+			results[k].Error = errAborted
+			if e.Conflict == ConflictOverwrite {
+				results[k].Error = "overwrite planned: cannot be used with atomic runs"
+			}
+		}
+		return results
+	}
+	order := p.apply(results, func(k int) int { return k }, false, opts)
+	if opts.Atomic && Failed(results) {
+		rollback(results, order, opts)
+	}
+	return results
+}
+
+// overwrites reports whether any entry of p replaces an existing file.
+func (p *Plan) overwrites() bool {
+	for _, e := range p.Entries {
+		if e.Conflict == ConflictOverwrite {
+			return true
+		}
+	}
+	return false
+}
+
+// preview returns the Result of e before execution.
+func (e PlanEntry) preview() Result {
+	return Result{
+		Path: e.Path, OldName: e.OldName, IntendedName: e.IntendedName, NewName: e.NewName, IsDir: e.IsDir,
+		AutoRenamed: e.AutoRenamed, Suffix: e.Suffix, Conflict: e.Conflict, Shortened: e.Shortened,
+	}
+}
+
+// apply executes the renames of p, storing the outcome of entry k in
+// results[index(k)] and keeping every path in results current as
+// directories move. It returns the indices of the attempted results in
+// execution order. With opts.Atomic it stops at the first failure.
+// With retry, a destination taken since planning is retried under the
+// next free suffix (see retriesTaken); otherwise the entry fails.
+func (p *Plan) apply(results []Result, index func(k int) int, retry bool, opts Options) []int {
+	entries := make([]int, len(p.Entries))
+	for k := range entries {
+		entries[k] = k
+	}
+
+	var order []int
+	failed := false
+	for _, group := range groupByDir(entries, func(k int) string { return filepath.Dir(p.Entries[k].Path) }) {
+		var moves []move
+		for _, k := range group {
+			e, i := p.Entries[k], index(k)
+			if failed {
+				results[i].Error = errAborted
+				continue
+			}
+			if err := e.check(); err != nil {
+				results[i].Error = err.Error()
+				failed = opts.Atomic
+				continue
+			}
+			moves = append(moves, move{
+				dir: filepath.Dir(e.Path), from: filepath.Base(e.Path), to: e.NewName, ref: i,
+				replace: e.Conflict == ConflictOverwrite,
+			})
+			order = append(order, i)
+		}
+
+		fold := len(moves) > 0 && opts.caseInsensitive(moves[0].dir)
+		reserved := make(map[string]bool, len(moves))
+		for _, m := range moves {
+			reserved[nameKey(m.to, fold)] = true
+		}
+
+		stopped := make(map[int]bool)
+		for _, m := range sequenceMoves(moves, fold) {
+			r := &results[m.ref]
+			if stopped[m.ref] {
+				continue
+			}
+			if failed {
+				r.Error = errAborted
+				stopped[m.ref] = true
+				continue
+			}
+			err := m.run()
+			if errors.Is(err, errDestinationExists) && !m.temp && retry {
+				err = m.retryUnique(r, reserved, fold, opts)
+			}
+			if err != nil {
+				r.Error = err.Error()
+				if r.Renamed {
+					r.Error += " (left at temporary name " + m.from + ")"
+				}
+				stopped[m.ref] = true
+				failed = opts.Atomic
+				continue
+			}
+			from, to := m.paths()
+			if r.IsDir {
+				relocate(results, from, to)
+			}
+			r.Path = to
+			r.Renamed = true
+			if msg := opts.record(from, to); msg != "" {
+				r.Error = msg
+				failed = opts.Atomic
+			}
+		}
+	}
+	return order
+}
+
+// retriesTaken reports whether Walk retries a destination taken after
+// planning under the next free suffix, which is the case for the suffix
+// and hash strategies. Apply never does: it only executes the plan.
+func (o Options) retriesTaken() bool {
+	return o.OnConflict == "" || o.OnConflict == ConflictSuffix || o.OnConflict == ConflictHash
+}
+
+// retryUnique renames m to the next free suffix of the intended name of r,
+// after another process took its destination since planning. Names
+// reserved by other moves of the directory are skipped. A name shortened
+// for Options.MaxPath does not grow. r is updated with the name used.
+func (m *move) retryUnique(r *Result, reserved map[string]bool, fold bool, opts Options) error {
+	intended := r.IntendedName
+	if intended == "" {
+		intended = r.NewName
+	}
+	if r.Shortened != "" {
+		opts.MaxLength = max(minLength, len(intended))
+	}
+	taken := func(fullPath string) bool {
+		if reserved[nameKey(filepath.Base(fullPath), fold)] {
+			return true
+		}
+		_, err := os.Lstat(fullPath)
+		return err == nil
+	}
+
+	for range maxRetries {
+		_, name, n := makeUnique(m.dir, intended, opts, taken)
+		m.to = name
+		err := m.run()
+		if err == nil {
+			r.NewName, r.AutoRenamed, r.Suffix, r.Conflict = name, true, n, ConflictSuffix
+		}
+		if !errors.Is(err, errDestinationExists) {
+			return err
+		}
+	}
+	return errDestinationExists
+}
+
+// maxRetries bounds the names tried when destinations keep being taken.
+const maxRetries = 100
+
+// check verifies that the source of e is still the fingerprinted file.
+func (e PlanEntry) check() error {
+	info, err := os.Lstat(e.Path)
+	if err != nil {
+		return err
+	}
+	if !e.Fingerprint.matches(info) {
+		return errors.New("source changed since planning")
+	}
+	return nil
+}
+
+// WriteFile saves the plan as indented JSON.
+func (p *Plan) WriteFile(path string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// ReadPlan loads a plan written by Plan.WriteFile.
+func ReadPlan(path string) (*Plan, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Plan
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if p.Version != PlanVersion {
+		return nil, fmt.Errorf("%s: unsupported plan version %d", path, p.Version)
+	}
+	return &p, nil
+}
@@ -0,0 +1,104 @@
+// plan_test.go
+// -------------
+// Unit tests for the plan-then-apply workflow.
+// Tests cover collision resolution at planning time, plan files,
+// and refusing sources that changed since planning.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// touch creates empty files in dir.
+func touch(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+}
+
+// TestNewPlan tests that collisions are resolved in the plan and nothing is renamed.
+func TestNewPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "My File.txt", "my_file.txt", "Other.PDF")
+
+	plan, results := NewPlan([]string{tmpDir}, Options{Case: "lower"})
+
+	if len(results) != 3 {
+		t.Fatalf("NewPlan() returned %d results, want 3", len(results))
+	}
+
+	got := make(map[string]string)
+	for _, e := range plan.Entries {
+		got[e.OldName] = e.NewName
+		if e.Fingerprint.Ino == 0 && e.Fingerprint.ModTime == 0 {
+			t.Errorf("entry %q has empty fingerprint", e.OldName)
+		}
+	}
+	want := map[string]string{
+		"My File.txt": "my_file_2.txt",
+		"Other.PDF":   "other.pdf",
+	}
+	if len(got) != len(want) {
+		t.Errorf("NewPlan() entries = %v, want %v", got, want)
+	}
+	for old, newName := range want {
+		if got[old] != newName {
+			t.Errorf("NewPlan() %q -> %q, want %q", old, got[old], newName)
+		}
+	}
+
+	// Planning must not touch the disk
+	if _, err := os.Stat(filepath.Join(tmpDir, "My File.txt")); err != nil {
+		t.Errorf("NewPlan() renamed a file: %v", err)
+	}
+}
+
+// TestApplyPlan tests executing a plan loaded from a plan file.
+func TestApplyPlan(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt", "Changed.txt")
+
+	plan, _ := NewPlan([]string{tmpDir}, Options{Case: "lower"})
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	if err := plan.WriteFile(planFile); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	loaded, err := ReadPlan(planFile)
+	if err != nil {
+		t.Fatalf("ReadPlan() error = %v", err)
+	}
+
+	// Modify one source after planning
+	changed := filepath.Join(tmpDir, "Changed.txt")
+	if err := os.WriteFile(changed, []byte("new content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(changed, time.Now(), time.Now().Add(time.Hour))
+
+	for _, r := range Apply(loaded, Options{}) {
+		switch r.OldName {
+		case "A File.txt":
+			if !r.Renamed || r.HasError() {
+				t.Errorf("Apply() %q: Renamed = %v, Error = %q, want renamed", r.OldName, r.Renamed, r.Error)
+			}
+		case "Changed.txt":
+			if r.Renamed || !r.HasError() {
+				t.Errorf("Apply() %q: Renamed = %v, want refused", r.OldName, r.Renamed)
+			}
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "a_file.txt")); err != nil {
+		t.Errorf("Apply() did not rename A File.txt: %v", err)
+	}
+	if _, err := os.Stat(changed); err != nil {
+		t.Errorf("Apply() renamed a changed source: %v", err)
+	}
+}
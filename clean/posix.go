@@ -0,0 +1,43 @@
+// posix.go
+// ---------
+// Ensures filenames are POSIX-safe by removing illegal characters,
+// collapsing duplicates, and trimming leading/trailing dots, underscores, and dashes.
+// Targets other than the default keep a different character set (see target.go).
+
+package clean
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	portableChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+	reMultiUnders = regexp.MustCompile(`_+`)
+	reMultiDashes = regexp.MustCompile(`-+`)
+)
+
+// Posixify restricts s to the POSIX portable filename character set
+// [A-Za-z0-9._-]. Spaces and other characters become '_', runs of '_' and
+// '-' are collapsed, and leading/trailing '.', '_' and '-' are trimmed.
+// An empty result is returned as "_".
+func Posixify(s string) string {
+	return filterChars(s, portableChars)
+}
+
+// filterChars is Posixify with the characters matched by disallowed
+// replaced instead (see profile).
+func filterChars(s string, disallowed *regexp.Regexp) string {
+	if s == "" {
+		return "_"
+	}
+	s = strings.ReplaceAll(s, " ", "_")
+	s = disallowed.ReplaceAllString(s, "_")
+	s = reMultiUnders.ReplaceAllString(s, "_")
+	s = reMultiDashes.ReplaceAllString(s, "-")
+	s = strings.Trim(s, "._-")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+// rename.go
+// ----------
+// Core renaming logic for Cleanfy.
+// Handles per-file name normalization, conflict resolution (--on-conflict),
+// dotfile preservation, skipping dangerous names (--on-dangerous), and
+// performing actual rename operations on disk.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProcessOne applies the rename process to a single file or directory.
+// A taken destination is handled by opts.OnConflict, like in Walk.
+// It returns a Result struct describing the outcome.
+func ProcessOne(path string, info os.FileInfo, opts Options) Result {
+	p, results := newPlan([]walkItem{{path: path, info: info}}, opts)
+	if opts.Execute {
+		p.apply(results, func(k int) int { return p.Entries[k].index }, opts.retriesTaken(), opts)
+	}
+	return results[0]
+}
+
+// propose computes the cleaned name for one entry without touching the disk.
+// It returns the preview Result; IntendedName is only set for entries
+// that can be renamed.
+func propose(path string, info os.FileInfo, opts Options) Result {
+	name := info.Name()
+	isDir := info.IsDir()
+
+	// "." and ".." (e.g. the walk root of `cleanfy -r .`) cannot be renamed
+	if name == "." || name == ".." {
+		return Result{Path: path, OldName: name, NewName: name, IsDir: isDir}
+	}
+
+	// 🧩 Dotfile handling
+	// By default, Cleanfy skips hidden files (starting with '.').
+	// Users can override this behavior with Options.Dotfiles (--dotfiles).
+	if strings.HasPrefix(name, ".") && len(name) > 1 && !opts.Dotfiles {
+		return Result{
+			Path:       path,
+			OldName:    name,
+			NewName:    name,
+			IsDir:      isDir,
+			Renamed:    false,
+			WasSkipped: true,
+		}
+	}
+
+	// Run the pipeline like CleanName (or ExplainName), keeping what the
+	// steps found out about the entry
+	ctx := &Context{Path: path, Name: name, IsDir: isDir, Opts: opts}
+	var steps []Trace
+	var trace *[]Trace
+	if opts.Explain {
+		trace = &steps
+	}
+	newName, err := opts.pipeline().run(ctx, trace)
+	r := Result{Path: path, OldName: name, IsDir: isDir, Detected: ctx.Detected, ExtMismatch: ctx.ExtMismatch, Dangerous: ctx.Dangerous, Steps: steps}
+	if err != nil {
+		r.Error = err.Error()
+		return r
+	}
+	if r.Dangerous != "" && opts.onDangerous() == DangerousSkip {
+		r.NewName, r.WasSkipped = name, true
+		return r
+	}
+	r.IntendedName, r.NewName = newName, newName
+	return r
+}
+
+// MakeUnique generates a non-conflicting name by appending a numeric suffix.
+// It returns the full path and the new name.
+// Example: "file.txt" → "file_2.txt" → "file_3.txt" → ...
+func MakeUnique(dir, name string) (string, string) {
+	fullPath, newName, _ := makeUnique(dir, name, Options{}, func(fullPath string) bool {
+		_, err := os.Stat(fullPath)
+		return !os.IsNotExist(err)
+	})
+	return fullPath, newName
+}
+
+// makeUnique is MakeUnique with the suffix layout of opts and a custom
+// check for taken paths. The base is shortened if needed so that the
+// suffixed name stays within the length limits of opts. It also returns
+// the number of the suffix added.
+func makeUnique(dir, name string, opts Options, taken func(fullPath string) bool) (string, string, int) {
+	base, ext := opts.splitName(name, false)
+	if opts.ShortNames != "" {
+		base = shortSets[opts.ShortNames].tail.ReplaceAllString(base, "") // Numbered from scratch
+	}
+	for n := opts.suffixStart(); ; n++ {
+		candidate := opts.fitName(base, opts.suffix(n), ext, false)
+		fullPath := filepath.Join(dir, candidate)
+		if !taken(fullPath) {
+			return fullPath, candidate, n
+		}
+	}
+}
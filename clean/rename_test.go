@@ -1,14 +1,15 @@
 // rename_test.go
 // ---------------
 // Unit tests for rename functionality.
-// Tests cover makeUnique collision handling and file-level normalization.
+// Tests cover MakeUnique collision handling and file-level normalization.
 
-package main
+package clean
 
 import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // TestMakeUnique tests the unique filename generation for collision handling.
@@ -69,47 +70,59 @@ func TestMakeUnique(t *testing.T) {
 				defer os.Remove(fpath)
 			}
 
-			fullPath, newName := makeUnique(tt.dir, tt.filename)
+			fullPath, newName := MakeUnique(tt.dir, tt.filename)
 
 			// Check the returned name
 			if newName != tt.wantBase {
-				t.Errorf("makeUnique() newName = %q, want %q", newName, tt.wantBase)
+				t.Errorf("MakeUnique() newName = %q, want %q", newName, tt.wantBase)
 			}
 
 			// Verify the full path matches
 			expectedFull := filepath.Join(tt.dir, tt.wantBase)
 			if fullPath != expectedFull {
-				t.Errorf("makeUnique() fullPath = %q, want %q", fullPath, expectedFull)
+				t.Errorf("MakeUnique() fullPath = %q, want %q", fullPath, expectedFull)
 			}
 
 			// Verify the generated file doesn't exist (yet)
 			if _, err := os.Stat(fullPath); !os.IsNotExist(err) {
-				t.Errorf("makeUnique() generated path already exists: %v", err)
+				t.Errorf("MakeUnique() generated path already exists: %v", err)
 			}
 		})
 	}
 }
 
-// TestProcessOneDryRun tests the dry-run mode of processOne (no actual renaming).
+// TestAutoRenamed tests that only entries that actually got a suffix are
+// reported as auto-renamed, with intended name and suffix number.
+func TestAutoRenamed(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Cleaned names with underscores, and one real clash with "report_final.txt"
+	touch(t, tmpDir, "My File.txt", "Old_Notes.txt", "report_final.txt", "Report Final.txt")
+
+	tests := map[string]Result{
+		"My File.txt":      {IntendedName: "my_file.txt", NewName: "my_file.txt"},
+		"Old_Notes.txt":    {IntendedName: "old_notes.txt", NewName: "old_notes.txt"},
+		"Report Final.txt": {IntendedName: "report_final.txt", NewName: "report_final_2.txt", AutoRenamed: true, Suffix: 2},
+	}
+
+	results := Walk([]string{tmpDir}, Options{Case: "lower", Execute: true})
+	for _, r := range results {
+		want, ok := tests[r.OldName]
+		if !ok {
+			continue
+		}
+		if r.IntendedName != want.IntendedName || r.NewName != want.NewName ||
+			r.AutoRenamed != want.AutoRenamed || r.Suffix != want.Suffix {
+			t.Errorf("Walk() %q: intended %q, new %q, auto %v, suffix %d; want %q, %q, %v, %d",
+				r.OldName, r.IntendedName, r.NewName, r.AutoRenamed, r.Suffix,
+				want.IntendedName, want.NewName, want.AutoRenamed, want.Suffix)
+		}
+	}
+}
+
+// TestProcessOneDryRun tests the dry-run mode of ProcessOne (no actual renaming).
 func TestProcessOneDryRun(t *testing.T) {
-	// Save original flags
-	oldFlagDo := flagDo
-	oldFlagCase := flagCase
-	oldFlagDateMode := flagDateMode
-	oldFlagDotfiles := flagDotfiles
-
-	defer func() {
-		flagDo = oldFlagDo
-		flagCase = oldFlagCase
-		flagDateMode = oldFlagDateMode
-		flagDotfiles = oldFlagDotfiles
-	}()
-
-	// Set up test flags for dry-run
-	flagDo = false
-	flagCase = "lower"
-	flagDateMode = ""
-	flagDotfiles = false
+	// Set up options for dry-run
+	opts := Options{Case: "lower"}
 
 	tmpDir := t.TempDir()
 
@@ -160,24 +173,24 @@ func TestProcessOneDryRun(t *testing.T) {
 				t.Fatalf("failed to stat test file: %v", err)
 			}
 
-			result := processOne(fpath, info)
+			result := ProcessOne(fpath, info, opts)
 
 			if result.OldName != tt.wantNormal {
-				t.Errorf("processOne() OldName = %q, want %q", result.OldName, tt.wantNormal)
+				t.Errorf("ProcessOne() OldName = %q, want %q", result.OldName, tt.wantNormal)
 			}
 
 			if result.NewName != tt.wantNew {
-				t.Errorf("processOne() NewName = %q, want %q", result.NewName, tt.wantNew)
+				t.Errorf("ProcessOne() NewName = %q, want %q", result.NewName, tt.wantNew)
 			}
 
 			// In dry-run mode, Renamed should be false
 			if result.Renamed {
-				t.Errorf("processOne() Renamed = %v, want false (dry-run mode)", result.Renamed)
+				t.Errorf("ProcessOne() Renamed = %v, want false (dry-run mode)", result.Renamed)
 			}
 
 			// Verify the file still exists with the original name
 			if _, err := os.Stat(fpath); err != nil {
-				t.Errorf("processOne() file was modified during dry-run: %v", err)
+				t.Errorf("ProcessOne() file was modified during dry-run: %v", err)
 			}
 		})
 	}
@@ -185,21 +198,7 @@ func TestProcessOneDryRun(t *testing.T) {
 
 // TestProcessOneWithDotfiles tests the dotfile handling flag.
 func TestProcessOneWithDotfiles(t *testing.T) {
-	oldFlagDo := flagDo
-	oldFlagCase := flagCase
-	oldFlagDateMode := flagDateMode
-	oldFlagDotfiles := flagDotfiles
-
-	defer func() {
-		flagDo = oldFlagDo
-		flagCase = oldFlagCase
-		flagDateMode = oldFlagDateMode
-		flagDotfiles = oldFlagDotfiles
-	}()
-
-	flagDo = false
-	flagCase = "lower"
-	flagDateMode = ""
+	opts := Options{Case: "lower"}
 
 	tmpDir := t.TempDir()
 
@@ -211,24 +210,63 @@ func TestProcessOneWithDotfiles(t *testing.T) {
 	info, _ := os.Stat(fpath)
 
 	// Test with dotfiles disabled (default)
-	flagDotfiles = false
-	result := processOne(fpath, info)
+	opts.Dotfiles = false
+	result := ProcessOne(fpath, info, opts)
 	if !result.WasSkipped {
-		t.Errorf("processOne() with dotfiles disabled: WasSkipped = %v, want true", result.WasSkipped)
+		t.Errorf("ProcessOne() with dotfiles disabled: WasSkipped = %v, want true", result.WasSkipped)
 	}
 	if result.NewName != ".MyEnv" {
-		t.Errorf("processOne() with dotfiles disabled: NewName = %q, want %q", result.NewName, ".MyEnv")
+		t.Errorf("ProcessOne() with dotfiles disabled: NewName = %q, want %q", result.NewName, ".MyEnv")
 	}
 
 	// Test with dotfiles enabled
-	flagDotfiles = true
-	result = processOne(fpath, info)
+	opts.Dotfiles = true
+	result = ProcessOne(fpath, info, opts)
 	if result.WasSkipped {
-		t.Errorf("processOne() with dotfiles enabled: WasSkipped = %v, want false", result.WasSkipped)
+		t.Errorf("ProcessOne() with dotfiles enabled: WasSkipped = %v, want false", result.WasSkipped)
+	}
+	// The leading dot is kept, so the file stays hidden
+	if result.NewName != ".myenv" {
+		t.Errorf("ProcessOne() with dotfiles enabled: NewName = %q, want %q", result.NewName, ".myenv")
+	}
+}
+
+// TestCleanNameDotfiles tests that hidden names keep one leading dot and
+// split their extension after it.
+func TestCleanNameDotfiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		isDir bool
+		opts  Options
+		want  string
+	}{
+		{"bashrc", ".bashrc", false, Options{}, ".bashrc"},
+		{"env-local", ".env.local", false, Options{}, ".env.local"},
+		{"env-local-upper", ".env.local", false, Options{Case: "upper"}, ".ENV.LOCAL"},
+		{"spaces-ext", ".My File.txt", false, Options{}, ".My_File.txt"},
+		{"spaces-ext-lower", ".My File.txt", false, Options{Case: "lower"}, ".my_file.txt"},
+		{"dir", ".My Config", true, Options{}, ".My_Config"},
+		{"double-dot", "..weird", false, Options{}, ".weird"},
+		{"only-dots", "...", false, Options{}, "_"},
+		{"trailing", ".cache_", true, Options{}, ".cache"},
+		{"reserved", ".con", false, Options{}, ".con"},
+		{"date", ".bashrc", false, Options{DateMode: "now", DateFormat: "2006"}, "." + time.Now().Format("2006") + "_bashrc"},
+		{"length", ".abcdefghij.txt", false, Options{MaxLength: 10}, ".abcde.txt"},
+		{"short-names", ".bashrc", false, Options{ShortNames: ShortDOS}, "BASHRC"},
+		{"iso9660", ".env.local", false, Options{Target: TargetISO9660}, "ENV.LOCAL"},
 	}
-	// The dot gets stripped during cleaning, so .MyEnv becomes myenv
-	if result.NewName != "myenv" {
-		t.Errorf("processOne() with dotfiles enabled: NewName = %q, want %q", result.NewName, "myenv")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CleanName("/tmp/"+tt.input, tt.input, tt.isDir, tt.opts)
+			if err != nil {
+				t.Fatalf("CleanName(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("CleanName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
 	}
 }
 
@@ -248,6 +286,6 @@ func BenchmarkMakeUnique(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		makeUnique(tmpDir, "file.txt")
+		MakeUnique(tmpDir, "file.txt")
 	}
 }
@@ -0,0 +1,72 @@
+// reserved.go
+// ------------
+// Windows naming rules, to avoid names that Windows clients cannot open.
+// Device names are reserved with any extension (con.tar.gz), including
+// COM0-9/LPT0-9 with superscript digits and the console names CONIN$ and
+// CONOUT$. Names may not contain <>:"/\|?* or control characters, and may
+// not end in a dot or space.
+
+package clean
+
+import (
+	"fmt"
+	"strings"
+)
+
+// windowsDevices holds the reserved device names, upper case.
+var windowsDevices = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"CONIN$": true, "CONOUT$": true,
+}
+
+func init() {
+	for _, digit := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "¹", "²", "³"} {
+		windowsDevices["COM"+digit] = true
+		windowsDevices["LPT"+digit] = true
+	}
+}
+
+// windowsForbidden holds the printable characters Windows does not allow in names.
+const windowsForbidden = `<>:"/\|?*`
+
+// IsWindowsReserved reports whether name is a Windows reserved device
+// name such as CON, NUL, COM1, LPT¹ or CONIN$, alone or followed by any
+// number of extensions ("con.tar.gz"). Case and spaces before the first
+// dot are ignored, as Windows does.
+func IsWindowsReserved(name string) bool {
+	stem, _, _ := strings.Cut(name, ".")
+	return windowsDevices[strings.ToUpper(strings.TrimRight(stem, " "))]
+}
+
+// CheckWindowsName returns an error if name is not a valid file name on
+// Windows: empty, "." or "..", a reserved device name, containing a
+// forbidden or control character, or ending in a dot or space.
+func CheckWindowsName(name string) error {
+	switch {
+	case name == "" || name == "." || name == "..":
+		return fmt.Errorf("invalid name %q", name)
+	case IsWindowsReserved(name):
+		return fmt.Errorf("reserved device name %q", name)
+	}
+	for _, r := range name {
+		if r < 0x20 || strings.ContainsRune(windowsForbidden, r) {
+			return fmt.Errorf("forbidden character %q in %q", r, name)
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("trailing dot or space in %q", name)
+	}
+	return nil
+}
+
+// trimTrailing removes trailing dots and spaces from the name made of
+// base and ext. A name left empty becomes "_".
+func trimTrailing(base, ext string) (string, string) {
+	if ext = strings.TrimRight(ext, ". "); ext == "" {
+		base = strings.TrimRight(base, ". ")
+	}
+	if base == "" {
+		base = "_"
+	}
+	return base, ext
+}
@@ -0,0 +1,109 @@
+// reserved_test.go
+// -----------------
+// Unit tests for the Windows naming rules.
+// Tests cover device names with any extensions, superscript digits,
+// console names, forbidden characters and trailing dots or spaces.
+
+package clean
+
+import "testing"
+
+// TestIsWindowsReserved tests reserved device name detection.
+func TestIsWindowsReserved(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"CON", true},
+		{"con", true},
+		{"Nul", true},
+		{"PRN.txt", true},
+		{"aux.tar.gz", true},
+		{"con.", true},
+		{"NUL .txt", true},
+		{"COM0", true},
+		{"COM9.log", true},
+		{"LPT0", true},
+		{"COM¹", true},
+		{"lpt³.txt", true},
+		{"CONIN$", true},
+		{"conout$.txt", true},
+		{"COM", false},
+		{"COM10", false},
+		{"LPT⁴", false},
+		{"CONSOLE", false},
+		{"_con.txt", false},
+		{"my.con", false},
+		{"icon.png", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsWindowsReserved(tt.name); got != tt.want {
+			t.Errorf("IsWindowsReserved(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCheckWindowsName tests the full set of Windows name rules.
+func TestCheckWindowsName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"report.pdf", false},
+		{"Report (Final).pdf", false},
+		{"a.b.c", false},
+		{"", true},
+		{".", true},
+		{"..", true},
+		{"con.tar.gz", true},
+		{"CONOUT$", true},
+		{"file:name.txt", true},
+		{"what?.txt", true},
+		{"a<b>", true},
+		{"pipe|name", true},
+		{"tab\tname", true},
+		{"name.", true},
+		{"name ", true},
+		{"name.txt ", true},
+	}
+
+	for _, tt := range tests {
+		if err := CheckWindowsName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("CheckWindowsName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCleanNameWindowsRules tests that cleaned names pass the Windows rules.
+func TestCleanNameWindowsRules(t *testing.T) {
+	tests := []struct {
+		target string
+		input  string
+		want   string
+	}{
+		{"", "con.tar.gz", "_con.tar.gz"},
+		{"", "COM0.txt", "_COM0.txt"},
+		{"", "LPT¹", "_LPT1"},
+		{"", "nul .txt", "_nul.txt"},
+		{TargetWindows, "CONIN$", "_CONIN$"},
+		{TargetWindows, "conout$.log", "_conout$.log"},
+		{TargetWindows, "notes.", "notes"},
+		{TargetWindows, "con.", "_con"},
+		{TargetWindows, "$", "$"},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanName("/tmp/"+tt.input, tt.input, false, Options{Target: tt.target})
+		if err != nil {
+			t.Fatalf("CleanName(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("CleanName(%q) target %q = %q, want %q", tt.input, tt.target, got, tt.want)
+		}
+		if err := CheckWindowsName(got); err != nil {
+			t.Errorf("CleanName(%q) = %q: %v", tt.input, got, err)
+		}
+	}
+}
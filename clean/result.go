@@ -0,0 +1,37 @@
+// result.go
+// ----------
+// Defines the Result struct used to represent the outcome of each rename operation.
+// Each Result entry records old/new names, errors, and flags such as Renamed and AutoRenamed.
+
+package clean
+
+// Result represents the outcome of processing one file or directory.
+type Result struct {
+	Path         string  `json:"path"`                    // Full path to the processed file or directory
+	OldName      string  `json:"old_name"`                // Original name
+	IntendedName string  `json:"intended_name,omitempty"` // Cleaned name, before collision handling
+	NewName      string  `json:"new_name"`                // Final name, including any collision suffix
+	IsDir        bool    `json:"is_dir"`                  // True if the entry is a directory
+	Renamed      bool    `json:"renamed"`                 // True if a rename actually occurred
+	WasSkipped   bool    `json:"skipped,omitempty"`       // True if the entry was skipped (e.g., dotfile)
+	AutoRenamed  bool    `json:"auto_renamed"`            // True if a suffix was added because IntendedName was taken
+	Suffix       int     `json:"suffix,omitempty"`        // Number of the collision suffix, if one was added
+	Conflict     string  `json:"conflict,omitempty"`      // Collision strategy applied if the cleaned name was taken
+	Shortened    string  `json:"shortened,omitempty"`     // Why the name was shortened to fit Options.MaxPath
+	Detected     string  `json:"detected,omitempty"`      // Content type found with Options.FixExt, as its canonical extension
+	ExtMismatch  bool    `json:"ext_mismatch,omitempty"`  // True if the extension does not match Detected and was kept
+	Dangerous    string  `json:"dangerous,omitempty"`     // Why the name looks like a disguised executable (see Options.OnDangerous)
+	RolledBack   bool    `json:"rolled_back,omitempty"`   // True if the rename was reverted because an atomic run failed
+	Error        string  `json:"error,omitempty"`         // Error message if any
+	Steps        []Trace `json:"steps,omitempty"`         // Intermediate names per pipeline step (with Options.Explain)
+}
+
+// HasError reports whether the result contains an error.
+func (r Result) HasError() bool {
+	return r.Error != ""
+}
+
+// IsChanged returns true if the name was altered or auto-renamed.
+func (r Result) IsChanged() bool {
+	return r.Renamed || r.AutoRenamed
+}
@@ -0,0 +1,103 @@
+// short.go
+// ---------
+// 8.3 short names for Cleanfy (--short-names).
+// Old embedded devices, DOS and ISO 9660 Level 1 discs only accept names
+// of at most 8 characters plus a 3-character extension. Longer or altered
+// names get a numeric tail like Windows does (LONGFI~1.TXT, or
+// LONGFI_1.TXT on ISO 9660); collisions count the tail up per directory
+// (LONGFI~2.TXT), in order of original name like every other collision
+// suffix.
+
+package clean
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Short name character sets for Options.ShortNames.
+const (
+	ShortDOS     = "dos"     // FAT 8.3 names
+	ShortISO9660 = "iso9660" // ISO 9660 Level 1 names (A-Z, 0-9 and _ only)
+)
+
+// Short name limits in bytes.
+const (
+	shortBase = 8
+	shortExt  = 3
+)
+
+// shortSet holds the rules of one short name character set.
+type shortSet struct {
+	chars  *regexp.Regexp // Characters not allowed in names
+	suffix string         // Numeric tail layout, with one %d verb
+	tail   *regexp.Regexp // Matches the numeric tail of a name
+}
+
+// shortSets holds the short name character sets. DOS names get tails like
+// Windows does (LONGFI~1); '~' is not an ISO 9660 d-character, so ISO
+// names get LONG_F_1. The DOS set keeps '~', so short names stay as they are.
+var shortSets = map[string]shortSet{
+	ShortDOS: {
+		chars:  regexp.MustCompile("[^A-Z0-9!#$%&'()\\-@^_`{}~]"),
+		suffix: "~%d",
+		tail:   regexp.MustCompile(`~\d+$`),
+	},
+	ShortISO9660: {
+		chars:  regexp.MustCompile(`[^A-Z0-9_]`),
+		suffix: "_%d",
+		tail:   regexp.MustCompile(`_\d+$`),
+	},
+}
+
+// shortPosixChars is the POSIX filter used with short names: the portable
+// character set plus '~'.
+var shortPosixChars = regexp.MustCompile(`[^A-Za-z0-9._~-]+`)
+
+// validateShort checks the short name options of o. Short names bring
+// their own collision suffix, which leaves no room for hashes.
+func (o Options) validateShort() error {
+	if o.ShortNames == "" {
+		return nil
+	}
+	if _, ok := shortSets[o.ShortNames]; !ok {
+		return fmt.Errorf("invalid short names %q: use one of dos | iso9660", o.ShortNames)
+	}
+	switch {
+	case o.SuffixFormat != "":
+		return fmt.Errorf("suffix format %q cannot be used with short names", o.SuffixFormat)
+	case o.OnConflict == ConflictHash:
+		return fmt.Errorf("on-conflict %q cannot be used with short names", o.OnConflict)
+	case o.TruncHash:
+		return fmt.Errorf("trunc-hash cannot be used with short names")
+	}
+	return nil
+}
+
+// shortName returns the 8.3 form of base and ext: upper case, without
+// dots, and other characters outside the set replaced by '_'. Names that
+// had to be changed beyond their case get the first numeric tail ("~1").
+func (o Options) shortName(base, ext string) (string, string) {
+	chars := shortSets[o.ShortNames].chars
+	filter := func(s string) string {
+		return chars.ReplaceAllString(strings.ToUpper(strings.ReplaceAll(s, ".", "")), "_")
+	}
+	b, e := filter(base), filter(ext)
+	if b == "" {
+		b = "_"
+	}
+	if len(b) <= shortBase && len(e) <= shortExt && b == strings.ToUpper(base) && e == strings.ToUpper(ext) {
+		return b, e
+	}
+	return o.splitName(o.fitName(b, o.suffix(1), e, false), e == "")
+}
+
+// fitShort is fitName for short names: the base with its suffix is cut
+// to 8 bytes and the extension to 3, within the name length limit of o.
+func (o Options) fitShort(base, suffix, ext string) string {
+	tail := joinName(suffix, truncate(ext, shortExt, math.MaxInt))
+	room := min(shortBase-len(suffix), o.maxLength()-len(tail))
+	return truncate(base, max(room, 0), math.MaxInt) + tail
+}
@@ -0,0 +1,152 @@
+// short_test.go
+// --------------
+// Unit tests for 8.3 short names (--short-names).
+// Tests cover name generation, numbered tails per directory and the
+// mapping of original to final paths.
+
+package clean
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+// TestShortName tests the 8.3 form of cleaned names.
+func TestShortName(t *testing.T) {
+	tests := []struct {
+		set   string
+		input string
+		isDir bool
+		want  string
+	}{
+		{ShortDOS, "readme.txt", false, "README.TXT"},
+		{ShortDOS, "Long File Name.txt", false, "LONG_F~1.TXT"},
+		{ShortDOS, "LongFileName.html", false, "LONGFI~1.HTM"},
+		{ShortDOS, "data.json", false, "DATA~1.JSO"},
+		{ShortDOS, "archive.tar.gz", false, "ARCHIV~1.GZ"},
+		{ShortDOS, "report-final.pdf", false, "REPORT~1.PDF"},
+		{ShortDOS, "Makefile", false, "MAKEFILE"},
+		{ShortDOS, "My Documents", true, "MY_DOC~1"},
+		{ShortDOS, "v1.2", true, "V12~1"},
+		{ShortISO9660, "my-notes.txt", false, "MY_NOT_1.TXT"},
+		{ShortISO9660, "LONG_F~1.TXT", false, "LONG_F_1.TXT"},
+		{ShortISO9660, "notes.txt", false, "NOTES.TXT"},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanName("/tmp/"+tt.input, tt.input, tt.isDir, Options{ShortNames: tt.set})
+		if err != nil {
+			t.Fatalf("CleanName(%q) error = %v", tt.input, err)
+		}
+		if got != tt.want {
+			t.Errorf("CleanName(%q) %s = %q, want %q", tt.input, tt.set, got, tt.want)
+		}
+	}
+}
+
+// TestShortNameCollisions tests that clashing short names count their tail
+// up in order of original name, skipping names on disk.
+func TestShortNameCollisions(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "LONG_F~1.TXT")
+	want := make(map[string]string)
+	for n := 1; n <= 11; n++ {
+		name := fmt.Sprintf("Long File %02d.txt", n)
+		touch(t, tmpDir, name)
+		want[name] = fmt.Sprintf("LONG_F~%d.TXT", n+1)
+	}
+	want["Long File 09.txt"] = "LONG_~10.TXT"
+	want["Long File 10.txt"] = "LONG_~11.TXT"
+	want["Long File 11.txt"] = "LONG_~12.TXT"
+
+	p, _ := NewPlan([]string{tmpDir}, Options{ShortNames: ShortDOS, Recursive: true})
+	got := planNames(p)
+	for old, name := range want {
+		if got[old] != name {
+			t.Errorf("%q -> %q, want %q", old, got[old], name)
+		}
+	}
+}
+
+// TestShortNamesISO9660 tests that ISO 9660 names, collision tails
+// included, only use d-characters.
+func TestShortNamesISO9660(t *testing.T) {
+	valid := regexp.MustCompile(`^[A-Z0-9_]{1,8}(\.[A-Z0-9_]{0,3})?$`)
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "LONG_F~1.TXT", "my-notes.txt", "My Notes.txt", "data.json", "Data (1).json", "~tmp", "v1.2")
+	for n := 1; n <= 11; n++ {
+		touch(t, tmpDir, fmt.Sprintf("Long File %02d.txt", n))
+	}
+
+	p, results := NewPlan([]string{tmpDir}, Options{ShortNames: ShortISO9660, Recursive: true})
+	for _, r := range results {
+		if r.Error != "" {
+			t.Errorf("%s: unexpected error %q", r.Path, r.Error)
+		}
+	}
+	for _, e := range p.Entries {
+		if e.Path != tmpDir && !valid.MatchString(e.NewName) {
+			t.Errorf("%q -> %q, not an ISO 9660 Level 1 name", e.OldName, e.NewName)
+		}
+	}
+}
+
+// TestShortNamesValidate tests options that cannot be combined with short names.
+func TestShortNamesValidate(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{ShortNames: ShortDOS}, false},
+		{Options{ShortNames: ShortISO9660, OnConflict: ConflictSkip}, false},
+		{Options{ShortNames: "udf"}, true},
+		{Options{ShortNames: ShortDOS, SuffixFormat: "_%d"}, true},
+		{Options{ShortNames: ShortDOS, OnConflict: ConflictHash}, true},
+		{Options{ShortNames: ShortDOS, TruncHash: true}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		}
+	}
+}
+
+// TestWalkMapName tests that renames are reported with their original and
+// final paths, in a dry run and when executed.
+func TestWalkMapName(t *testing.T) {
+	for _, execute := range []bool{false, true} {
+		t.Run(fmt.Sprintf("execute=%v", execute), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			docs := filepath.Join(tmpDir, "My Documents")
+			if err := os.Mkdir(docs, 0755); err != nil {
+				t.Fatalf("failed to create test dir: %v", err)
+			}
+			touch(t, docs, "Long File Name.txt", "README.TXT")
+
+			mapping := make(map[string]string)
+			opts := Options{ShortNames: ShortDOS, Recursive: true, Execute: execute}
+			opts.MapName = func(original, final string) { mapping[original] = final }
+			Walk([]string{docs}, opts)
+
+			want := map[string]string{
+				filepath.Join(docs, "Long File Name.txt"): filepath.Join(tmpDir, "MY_DOC~1", "LONG_F~1.TXT"),
+				docs: filepath.Join(tmpDir, "MY_DOC~1"),
+			}
+			if len(mapping) != len(want) {
+				t.Errorf("MapName got %v, want %v", mapping, want)
+			}
+			for original, final := range want {
+				if mapping[original] != final {
+					t.Errorf("MapName(%q) = %q, want %q", original, mapping[original], final)
+				}
+				if _, err := os.Stat(final); (err == nil) != execute {
+					t.Errorf("Stat(%q) error = %v, execute %v", final, err, execute)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// sniff.go
+// ---------
+// Content sniffing for Cleanfy (--fix-ext).
+// Files from email exports and browsers often have no extension or the
+// wrong one. The first bytes of a file identify common types (PNG, JPEG,
+// PDF, ZIP and Office documents, GZIP, MP4, ...); a missing extension is
+// added and a mismatched one is flagged or replaced.
+
+package clean
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Extension fixing modes for Options.FixExt.
+const (
+	FixExtAdd     = "add"     // Add missing extensions, flag mismatched ones
+	FixExtReplace = "replace" // Add missing extensions, replace mismatched ones
+)
+
+// sniffLen is the number of bytes read to detect the content type.
+const sniffLen = 8192
+
+// fileType is one detectable content type.
+type fileType struct {
+	ext     string   // Canonical extension
+	aliases []string // Other extensions accepted for the content
+	match   func(b []byte) bool
+}
+
+// prefix returns a matcher for content starting with any of magic.
+func prefix(magic ...string) func(b []byte) bool {
+	return func(b []byte) bool {
+		for _, m := range magic {
+			if bytes.HasPrefix(b, []byte(m)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// riff returns a matcher for RIFF content of the given form type.
+func riff(form string) func(b []byte) bool {
+	return func(b []byte) bool {
+		return len(b) >= 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == form
+	}
+}
+
+// ftyp returns a matcher for ISO media files (MP4, QuickTime, HEIF) whose
+// major brand starts with any of brands; no brands match all of them.
+func ftyp(brands ...string) func(b []byte) bool {
+	return func(b []byte) bool {
+		if len(b) < 12 || string(b[4:8]) != "ftyp" {
+			return false
+		}
+		if len(brands) == 0 {
+			return true
+		}
+		return prefix(brands...)(b[8:12])
+	}
+}
+
+// zipWith returns a matcher for ZIP archives whose first entries contain
+// all of names, such as the parts of an Office document.
+func zipWith(names ...string) func(b []byte) bool {
+	return func(b []byte) bool {
+		if !bytes.HasPrefix(b, []byte("PK\x03\x04")) {
+			return false
+		}
+		for _, n := range names {
+			if !bytes.Contains(b, []byte(n)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// fileTypes lists the detectable types, more specific ones first.
+// Documents stored as ZIP archives also accept .zip.
+var fileTypes = []fileType{
+	{"png", nil, prefix("\x89PNG\r\n\x1a\n")},
+	{"jpg", []string{"jpeg", "jpe", "jfif"}, prefix("\xff\xd8\xff")},
+	{"gif", nil, prefix("GIF87a", "GIF89a")},
+	{"webp", nil, riff("WEBP")},
+	{"tif", []string{"tiff", "dng", "cr2", "nef", "arw", "orf", "pef", "srw", "erf", "nrw"}, prefix("II*\x00", "MM\x00*")},
+	{"psd", nil, prefix("8BPS")},
+	{"pdf", []string{"ai"}, prefix("%PDF-")},
+	{"docx", []string{"docm", "dotx", "dotm", "zip"}, zipWith("[Content_Types].xml", "word/")},
+	{"xlsx", []string{"xlsm", "xltx", "xltm", "zip"}, zipWith("[Content_Types].xml", "xl/")},
+	{"pptx", []string{"pptm", "potx", "ppsx", "zip"}, zipWith("[Content_Types].xml", "ppt/")},
+	{"epub", []string{"zip"}, zipWith("mimetypeapplication/epub+zip")},
+	{"odt", []string{"zip"}, zipWith("mimetypeapplication/vnd.oasis.opendocument.text")},
+	{"ods", []string{"zip"}, zipWith("mimetypeapplication/vnd.oasis.opendocument.spreadsheet")},
+	{"odp", []string{"zip"}, zipWith("mimetypeapplication/vnd.oasis.opendocument.presentation")},
+	{"zip", []string{
+		"jar", "war", "apk", "aab", "ipa", "xpi", "crx", "whl", "nupkg", "vsix", "kmz", "cbz", "3mf",
+		"docx", "docm", "xlsx", "xlsm", "pptx", "pptm", "odt", "ods", "odp", "odg", "epub",
+		"pages", "numbers", "key", "sketch",
+	}, prefix("PK\x03\x04", "PK\x05\x06")},
+	{"gz", []string{"tgz", "svgz"}, prefix("\x1f\x8b\x08")},
+	{"bz2", []string{"tbz", "tbz2"}, prefix("BZh1", "BZh2", "BZh3", "BZh4", "BZh5", "BZh6", "BZh7", "BZh8", "BZh9")},
+	{"xz", []string{"txz"}, prefix("\xfd7zXZ\x00")},
+	{"zst", []string{"tzst"}, prefix("\x28\xb5\x2f\xfd")},
+	{"7z", nil, prefix("7z\xbc\xaf\x27\x1c")},
+	{"rar", []string{"cbr"}, prefix("Rar!\x1a\x07")},
+	{"mov", []string{"qt", "mp4"}, ftyp("qt  ")},
+	{"m4a", []string{"m4b", "mp4"}, ftyp("M4A ", "M4B ")},
+	{"avif", nil, ftyp("avif", "avis")},
+	{"heic", []string{"heif", "hif"}, ftyp("heic", "heix", "hevc", "heim", "heis", "mif1", "msf1")},
+	{"3gp", []string{"3g2", "mp4"}, ftyp("3g")},
+	{"mp4", []string{"m4v", "m4a", "m4b", "m4p", "mov", "3gp", "f4v"}, ftyp()},
+	{"webm", []string{"mkv"}, func(b []byte) bool {
+		return bytes.HasPrefix(b, []byte("\x1a\x45\xdf\xa3")) && bytes.Contains(b[:min(len(b), 64)], []byte("webm"))
+	}},
+	{"mkv", []string{"mka", "mk3d", "mks", "webm"}, prefix("\x1a\x45\xdf\xa3")},
+	{"avi", nil, riff("AVI ")},
+	{"wav", []string{"wave"}, riff("WAVE")},
+	{"mp3", nil, prefix("ID3", "\xff\xfb", "\xff\xf3", "\xff\xf2")},
+	{"ogg", []string{"oga", "ogv", "opus", "spx", "ogx"}, prefix("OggS")},
+	{"flac", nil, prefix("fLaC")},
+	{"woff", nil, prefix("wOFF")},
+	{"woff2", nil, prefix("wOF2")},
+	{"wasm", nil, prefix("\x00asm")},
+	{"sqlite", []string{"sqlite3", "db", "db3"}, prefix("SQLite format 3\x00")},
+	{"exe", []string{"dll", "sys", "scr", "cpl", "ocx", "drv", "efi", "mui"}, func(b []byte) bool {
+		return len(b) >= 64 && bytes.HasPrefix(b, []byte("MZ"))
+	}},
+}
+
+// validateFixExt checks the extension fixing mode of o.
+func (o Options) validateFixExt() error {
+	switch o.FixExt {
+	case "", FixExtAdd, FixExtReplace:
+		return nil
+	}
+	return fmt.Errorf("invalid fix-ext %q: use one of add | replace", o.FixExt)
+}
+
+// DetectExt returns the canonical extension (without the dot) of the
+// content starting with header, such as "png" or "docx", or "" if the
+// type is not known. Office documents are told apart from plain ZIP
+// archives if header holds their first entries; 8 KiB is enough in
+// practice.
+func DetectExt(header []byte) string {
+	if t := detect(header); t != nil {
+		return t.ext
+	}
+	return ""
+}
+
+// detect returns the type of the content starting with header, or nil.
+func detect(header []byte) *fileType {
+	for i := range fileTypes {
+		if fileTypes[i].match(header) {
+			return &fileTypes[i]
+		}
+	}
+	return nil
+}
+
+// accepts reports whether ext is a usual extension for content of type
+// t. Only the last part of a multi-part extension counts (tar.gz is gz).
+func (t *fileType) accepts(ext string) bool {
+	ext = strings.ToLower(ext[strings.LastIndexByte(ext, '.')+1:])
+	if ext == t.ext || defaultExtMap[ext] == t.ext {
+		return true
+	}
+	for _, a := range t.aliases {
+		if ext == a {
+			return true
+		}
+	}
+	return false
+}
+
+// sniffFile returns the type of the regular file at path, or nil if it
+// cannot be read or its type is not known.
+func sniffFile(path string) *fileType {
+	// Only regular files: reading a FIFO or device could block
+	if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	b := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, b)
+	return detect(b[:n])
+}
+
+// hasLetter reports whether s contains a letter. Extensions without one,
+// such as the "2024" of "scan.12.05.2024", are taken as part of the name.
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// Add or fix the extension from the file content
+func sniffStep(ctx *Context, base, ext string) (string, string, error) {
+	if ctx.Opts.FixExt == "" || ctx.IsDir {
+		return base, ext, nil
+	}
+	t := sniffFile(ctx.Path)
+	if t == nil {
+		return base, ext, nil
+	}
+	ctx.Detected = t.ext
+	detected := ctx.Opts.casedExt(t.ext)
+
+	if ext != "" && !hasLetter(ext) {
+		sep := "."
+		if ctx.Opts.singleDot() {
+			sep = "_"
+		}
+		base, ext = base+sep+ext, ""
+	}
+	switch {
+	case ext == "":
+		ext = detected
+	case t.accepts(ext):
+	case ctx.Opts.FixExt == FixExtReplace:
+		ext = detected
+	default:
+		ctx.ExtMismatch = true
+	}
+	return base, ext, nil
+}
@@ -0,0 +1,148 @@
+// sniff_test.go
+// --------------
+// Unit tests for content sniffing (--fix-ext).
+// Tests cover type detection from magic bytes and adding, flagging and
+// replacing extensions.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Sample file contents, long enough for every matcher
+var (
+	samplePNG  = "\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"
+	samplePDF  = "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"
+	sampleDOCX = "PK\x03\x04\x14\x00\x06\x00[Content_Types].xml....PK\x03\x04word/document.xml"
+	sampleText = "Dear customer,\nplease find attached...\n"
+)
+
+// TestDetectExt tests content type detection from the first bytes.
+func TestDetectExt(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"png", samplePNG, "png"},
+		{"jpeg", "\xff\xd8\xff\xe0\x00\x10JFIF", "jpg"},
+		{"gif", "GIF89a\x01\x00", "gif"},
+		{"pdf", samplePDF, "pdf"},
+		{"docx", sampleDOCX, "docx"},
+		{"xlsx", "PK\x03\x04[Content_Types].xml xl/workbook.xml", "xlsx"},
+		{"epub", "PK\x03\x04\x0a\x00\x00\x00\x00\x00mimetypeapplication/epub+zip", "epub"},
+		{"zip", "PK\x03\x04\x14\x00\x00\x00notes.txt", "zip"},
+		{"gzip", "\x1f\x8b\x08\x00\x00\x00\x00\x00", "gz"},
+		{"mp4", "\x00\x00\x00\x20ftypisom\x00\x00\x02\x00", "mp4"},
+		{"mov", "\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00", "mov"},
+		{"heic", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00", "heic"},
+		{"webp", "RIFF\x24\x00\x00\x00WEBPVP8 ", "webp"},
+		{"wav", "RIFF\x24\x00\x00\x00WAVEfmt ", "wav"},
+		{"text", sampleText, ""},
+		{"empty", "", ""},
+		{"short-mz", "MZ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DetectExt([]byte(tt.header)); got != tt.want {
+			t.Errorf("DetectExt(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestFixExt tests adding missing extensions and flagging or replacing mismatched ones.
+func TestFixExt(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		fixExt       string
+		caseMode     string
+		wantName     string
+		wantDetected string
+		wantMismatch bool
+	}{
+		{"attachment", samplePNG, FixExtAdd, "", "attachment.png", "png", false},
+		{"photo.png", samplePNG, FixExtAdd, "", "photo.png", "png", false},
+		{"photo.JPEG", "\xff\xd8\xff\xe1\x00\x18Exif", FixExtAdd, "", "photo.JPEG", "jpg", false},
+		{"invoice.txt", samplePDF, FixExtAdd, "", "invoice.txt", "pdf", true},
+		{"invoice.txt", samplePDF, FixExtReplace, "", "invoice.pdf", "pdf", false},
+		{"scan.12.05.2024", samplePDF, FixExtAdd, "", "scan.12.05.2024.pdf", "pdf", false},
+		{"report.zip", sampleDOCX, FixExtAdd, "", "report.zip", "docx", false},
+		{"report.docx", sampleDOCX, FixExtAdd, "", "report.docx", "docx", false},
+		{"backup.tar.gz", "\x1f\x8b\x08\x00", FixExtReplace, "", "backup.tar.gz", "gz", false},
+		{"letter", sampleText, FixExtAdd, "", "letter", "", false},
+		{"attachment", samplePNG, "", "", "attachment", "", false},
+		{"photo", samplePNG, FixExtAdd, "upper", "PHOTO.PNG", "png", false},
+		{"report.txt", samplePDF, FixExtReplace, "upper", "REPORT.PDF", "pdf", false},
+		{"Photo", samplePNG, FixExtAdd, "lower", "photo.png", "png", false},
+		{"my photo", samplePNG, FixExtAdd, "title", "My_Photo.png", "png", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.fixExt, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.name)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+			info, err := os.Lstat(path)
+			if err != nil {
+				t.Fatalf("failed to stat test file: %v", err)
+			}
+
+			r := ProcessOne(path, info, Options{FixExt: tt.fixExt, Case: tt.caseMode})
+			if r.NewName != tt.wantName || r.Detected != tt.wantDetected || r.ExtMismatch != tt.wantMismatch {
+				t.Errorf("ProcessOne() = %q (detected %q, mismatch %v), want %q (detected %q, mismatch %v)",
+					r.NewName, r.Detected, r.ExtMismatch, tt.wantName, tt.wantDetected, tt.wantMismatch)
+			}
+			if _, err := os.Stat(path); err != nil {
+				t.Errorf("file was renamed in preview: %v", err)
+			}
+		})
+	}
+}
+
+// TestFixExtDirsAndExplain tests that directories are left alone and the
+// added extension shows in the sniff step of the trace.
+func TestFixExtDirsAndExplain(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "export"), 0755); err != nil {
+		t.Fatalf("failed to create test dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "scan"), []byte(samplePDF), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	results := Walk([]string{tmpDir}, Options{FixExt: FixExtAdd, Explain: true, Recursive: true})
+	for _, r := range results {
+		switch r.OldName {
+		case "export":
+			if r.NewName != "export" || r.Detected != "" {
+				t.Errorf("dir -> %q (detected %q), want unchanged", r.NewName, r.Detected)
+			}
+		case "scan":
+			var steps []string
+			for _, s := range r.Steps {
+				steps = append(steps, s.Step+"="+s.Name)
+			}
+			if trace := strings.Join(steps, " "); !strings.Contains(trace, "case=scan sniff=scan.pdf") {
+				t.Errorf("trace = %s, want sniff step adding .pdf", trace)
+			}
+		}
+	}
+}
+
+// TestFixExtValidate tests the accepted fix-ext modes.
+func TestFixExtValidate(t *testing.T) {
+	for _, mode := range []string{"", FixExtAdd, FixExtReplace} {
+		if err := (Options{FixExt: mode}).Validate(); err != nil {
+			t.Errorf("Validate(%q) error = %v", mode, err)
+		}
+	}
+	if err := (Options{FixExt: "guess"}).Validate(); err == nil {
+		t.Errorf("Validate(guess) error = nil, want error")
+	}
+}
@@ -0,0 +1,106 @@
+// target.go
+// ----------
+// Target filesystem profiles for Cleanfy (--target).
+// A profile describes the names a destination accepts: the characters
+// kept, reserved names, whether names differing in case collide, name and
+// path length limits, and whether names may end in a dot or space.
+// Names cleaned for a target are valid there, so one tree can be copied to
+// a NAS, Windows laptops and S3 alike.
+
+package clean
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Target filesystem profiles for Options.Target.
+const (
+	TargetPosix    = "posix"    // Linux and Unix filesystems (ext4, XFS, ZFS, NFS)
+	TargetWindows  = "windows"  // NTFS and SMB shares used from Windows
+	TargetMacOS    = "macos"    // APFS and HFS+ with the default case-insensitive setup
+	TargetFAT32    = "fat32"    // FAT32 with long names (USB sticks, SD cards)
+	TargetExFAT    = "exfat"    // exFAT (large removable drives)
+	TargetISO9660  = "iso9660"  // ISO 9660 Level 2 (optical discs)
+	TargetS3       = "s3"       // Amazon S3 and compatible object stores
+	TargetPortable = "portable" // Valid on all of the above except ISO 9660
+)
+
+// profile holds the naming rules of one target.
+type profile struct {
+	disallowed      *regexp.Regexp         // Characters replaced by '_'
+	upper           bool                   // Names are upper case only
+	reserved        func(name string) bool // Reports reserved names (nil: none)
+	caseInsensitive bool                   // Names differing only in case collide
+	maxName         int                    // Name length limit in bytes
+	maxPath         int                    // Path length limit in bytes (0: none)
+	noTrailing      bool                   // Names may not end in a dot or space
+}
+
+var (
+	// Printable ASCII accepted by Windows, minus space
+	windowsChars = regexp.MustCompile("[^A-Za-z0-9._\\-!#$%&'()+,;=@\\[\\]^`{}~]+")
+	// ISO 9660 d-characters; the only dot separates the extension
+	isoChars = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+	// S3 object key characters that need no special handling
+	s3Chars = regexp.MustCompile(`[^A-Za-z0-9._\-!*'()]+`)
+)
+
+// profiles maps target names to their rules. The empty name holds the
+// rules used without a target: portable characters, Windows device names
+// reserved, case handling detected per directory and no path limit.
+var profiles = map[string]profile{
+	"":             {disallowed: portableChars, reserved: IsWindowsReserved, maxName: DefaultMaxLength},
+	TargetPosix:    {disallowed: portableChars, maxName: 255, maxPath: 4096},
+	TargetWindows:  {disallowed: windowsChars, reserved: IsWindowsReserved, caseInsensitive: true, maxName: 255, maxPath: 260, noTrailing: true},
+	TargetMacOS:    {disallowed: windowsChars, caseInsensitive: true, maxName: 255, maxPath: 1024},
+	TargetFAT32:    {disallowed: windowsChars, reserved: IsWindowsReserved, caseInsensitive: true, maxName: 255, maxPath: 255, noTrailing: true},
+	TargetExFAT:    {disallowed: windowsChars, reserved: IsWindowsReserved, caseInsensitive: true, maxName: 255, noTrailing: true},
+	TargetISO9660:  {disallowed: isoChars, upper: true, caseInsensitive: true, maxName: 31, maxPath: 255, noTrailing: true},
+	TargetS3:       {disallowed: s3Chars, maxName: 255, maxPath: 1024},
+	TargetPortable: {disallowed: portableChars, reserved: IsWindowsReserved, caseInsensitive: true, maxName: 255, maxPath: 255, noTrailing: true},
+}
+
+// validateTarget checks the target of o and that collision suffixes are
+// valid on it.
+func (o Options) validateTarget() error {
+	t, ok := profiles[o.Target]
+	if !ok {
+		return fmt.Errorf("invalid target %q: use one of posix | windows | macos | fat32 | exfat | iso9660 | s3 | portable", o.Target)
+	}
+	if o.Target != "" && o.SuffixFormat != "" {
+		if t.disallowed.MatchString(fmt.Sprintf(o.SuffixFormat, 1)) {
+			return fmt.Errorf("invalid suffix format %q: not valid on target %s", o.SuffixFormat, o.Target)
+		}
+	}
+	return nil
+}
+
+// target returns the naming rules of o.Target.
+func (o Options) target() profile {
+	return profiles[o.Target]
+}
+
+// disallowed returns the characters replaced by '_' when cleaning names.
+func (o Options) disallowed() *regexp.Regexp {
+	if o.ShortNames != "" {
+		return shortPosixChars
+	}
+	return o.target().disallowed
+}
+
+// singleDot reports whether names may only contain the dot before the
+// extension, as short names and ISO 9660 names. Such names cannot be
+// hidden and have no multi-part extensions.
+func (o Options) singleDot() bool {
+	return o.ShortNames != "" || o.Target == TargetISO9660
+}
+
+// cased returns s in the case required by t.
+func (t profile) cased(s string) string {
+	if t.upper {
+		return strings.ToUpper(s)
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+// target_test.go
+// ---------------
+// Unit tests for target filesystem profiles (--target).
+// Tests cover the characters kept, reserved names, case and length rules
+// per target and their effect on collisions.
+
+package clean
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCleanNameTarget tests that names follow the rules of the target.
+func TestCleanNameTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		input  string
+		want   string
+	}{
+		{"", "Report (Final).PDF", "Report_Final.PDF"},
+		{TargetPosix, "Report (Final).PDF", "Report_Final.PDF"},
+		{TargetWindows, "Report (Final).PDF", "Report_(Final).PDF"},
+		{TargetS3, "Report (Final).PDF", "Report_(Final).PDF"},
+		{TargetISO9660, "Report (Final).PDF", "REPORT_FINAL.PDF"},
+		{TargetWindows, "Price $5 & More.txt", "Price_$5_&_More.txt"},
+		{TargetS3, "Price $5 & More.txt", "Price_5_More.txt"},
+		{TargetPortable, "Price $5 & More.txt", "Price_5_More.txt"},
+		{TargetMacOS, "a+b=c.txt", "a+b=c.txt"},
+		{"", "con.txt", "_con.txt"},
+		{TargetWindows, "con.txt", "_con.txt"},
+		{TargetFAT32, "AUX", "_AUX"},
+		{TargetPosix, "con.txt", "con.txt"},
+		{TargetS3, "nul", "nul"},
+		{TargetISO9660, "archive.tar.gz", "ARCHIVE_TAR.GZ"},
+		{TargetISO9660, "A Very Long Document Name For Disc.txt", "A_VERY_LONG_DOCUMENT_NAME_F.TXT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+"/"+tt.input, func(t *testing.T) {
+			got, err := CleanName("/tmp/"+tt.input, tt.input, false, Options{Target: tt.target})
+			if err != nil {
+				t.Fatalf("CleanName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CleanName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestTargetLimits tests the length limits and case handling taken from the target.
+func TestTargetLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	tests := []struct {
+		opts            Options
+		maxLength       int
+		maxPath         int
+		caseInsensitive bool
+	}{
+		{Options{}, DefaultMaxLength, 0, false},
+		{Options{Target: TargetPosix}, 255, 4096, false},
+		{Options{Target: TargetWindows}, 255, 260, true},
+		{Options{Target: TargetISO9660}, 31, 255, true},
+		{Options{Target: TargetWindows, MaxLength: 100, MaxPath: 200}, 100, 200, true},
+		{Options{Target: TargetWindows, MaxPath: -1, FSCase: FSCaseSensitive}, 255, 0, false},
+	}
+
+	for _, tt := range tests {
+		o := tt.opts
+		if got := o.maxLength(); got != tt.maxLength {
+			t.Errorf("%+v: maxLength() = %d, want %d", o, got, tt.maxLength)
+		}
+		if got := o.maxPath(); got != tt.maxPath {
+			t.Errorf("%+v: maxPath() = %d, want %d", o, got, tt.maxPath)
+		}
+		if got := o.caseInsensitive(tmpDir); got != tt.caseInsensitive {
+			t.Errorf("%+v: caseInsensitive() = %v, want %v", o, got, tt.caseInsensitive)
+		}
+	}
+}
+
+// TestTargetSuffixFormat tests that collision suffixes must be valid on the target.
+func TestTargetSuffixFormat(t *testing.T) {
+	tests := []struct {
+		opts    Options
+		wantErr bool
+	}{
+		{Options{SuffixFormat: " (%d)"}, false},
+		{Options{Target: TargetWindows, SuffixFormat: "_(%d)"}, false},
+		{Options{Target: TargetWindows, SuffixFormat: " (%d)"}, true},
+		{Options{Target: TargetISO9660, SuffixFormat: "-%03d"}, true},
+		{Options{Target: TargetISO9660, SuffixFormat: "_%d"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.opts, err, tt.wantErr)
+		}
+	}
+}
+
+// TestTargetCollisions tests that names differing only in case collide on
+// case-insensitive targets, even on a case-sensitive disk.
+func TestTargetCollisions(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{TargetPosix, "NOTES.txt"},
+		{TargetWindows, "NOTES_2.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			touch(t, tmpDir, "Notes.txt", "NOTES .txt")
+
+			p, _ := NewPlan([]string{filepath.Join(tmpDir, "NOTES .txt")}, Options{Target: tt.target, FSCase: FSCaseAuto})
+			if got := planNames(p)["NOTES .txt"]; got != tt.want {
+				t.Errorf("NewPlan() -> %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
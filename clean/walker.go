@@ -0,0 +1,165 @@
+// walker.go
+// ----------
+// Directory traversal logic for Cleanfy.
+// Supports recursive and non-recursive file walking via WalkDir.
+// All entries are collected first and renamed deepest-first, so a
+// directory is only renamed after everything inside it.
+
+package clean
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Walk walks through all target paths (files/directories), plans the
+// renames of every entry and executes the plan when opts.Execute is set.
+// It supports both recursive (opts.Recursive) and non-recursive modes.
+// With no targets, the current directory is processed.
+// Results are returned in walk order; entries are renamed children
+// before parents, and paths inside renamed directories are reported at
+// their final location. Dry-run results show the same collision
+// suffixes that execution would produce. A destination taken by another
+// process in the meantime moves to the next free suffix with the suffix
+// and hash strategies.
+// With opts.Atomic, nothing is renamed if planning reported errors, the
+// first failed rename stops the run, and completed renames are rolled back.
+func Walk(targets []string, opts Options) []Result {
+	p, results := newPlan(collect(targets, opts.Recursive), opts)
+	if opts.MapName != nil {
+		defer p.mapNames(results, opts)
+	}
+	if !opts.Execute {
+		return results
+	}
+
+	if opts.Atomic && Failed(results) {
+		for _, e := range p.Entries {
+			results[e.index].Error = errAborted
+		}
+		return results
+	}
+
+	order := p.apply(results, func(k int) int { return p.Entries[k].index }, opts.retriesTaken(), opts)
+	if opts.Atomic && Failed(results) {
+		rollback(results, order, opts)
+	}
+	return results
+}
+
+// mapNames passes the original and final path of every entry of p that
+// was renamed (or would be, in a dry run) to opts.MapName.
+func (p *Plan) mapNames(results []Result, opts Options) {
+	renamed := make(map[string]string, len(p.Entries)) // Original path -> final name
+	var mapped []string
+	for _, e := range p.Entries {
+		r := results[e.index]
+		if opts.Execute && (!r.Renamed || r.RolledBack) {
+			continue
+		}
+		renamed[e.Path] = r.NewName
+		mapped = append(mapped, e.Path)
+	}
+
+	var final func(path string) string
+	final = func(path string) string {
+		dir := filepath.Dir(path)
+		if dir != path {
+			dir = final(dir)
+		}
+		name, ok := renamed[path]
+		if !ok {
+			name = filepath.Base(path)
+		}
+		return filepath.Join(dir, name)
+	}
+	for _, path := range mapped {
+		opts.MapName(path, final(path))
+	}
+}
+
+// walkItem is one entry found by collect, or an error met while walking.
+type walkItem struct {
+	path string
+	info os.FileInfo
+	err  error
+}
+
+// collect lists every entry of targets in walk order without renaming anything.
+func collect(targets []string, recursive bool) []walkItem {
+	if len(targets) == 0 {
+		targets = []string{"."}
+	}
+
+	var items []walkItem
+	for _, root := range targets {
+		// "Bad Dir/" (as completed by shells) names the directory itself
+		root = filepath.Clean(root)
+		info, err := os.Stat(root)
+		if err != nil {
+			items = append(items, walkItem{path: root, err: err})
+			continue
+		}
+
+		if info.IsDir() {
+			if recursive {
+				filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+					if err != nil {
+						items = append(items, walkItem{path: path, err: err})
+						return nil
+					}
+					info, err := d.Info()
+					items = append(items, walkItem{path: path, info: info, err: err})
+					return nil
+				})
+			} else {
+				entries, err := os.ReadDir(root)
+				if err != nil {
+					items = append(items, walkItem{path: root, err: err})
+					continue
+				}
+				for _, e := range entries {
+					info, err := e.Info()
+					items = append(items, walkItem{path: filepath.Join(root, e.Name()), info: info, err: err})
+				}
+			}
+		} else {
+			items = append(items, walkItem{path: root, info: info})
+		}
+	}
+	return items
+}
+
+// deepestFirst returns the indices of items ordered by decreasing path
+// depth, keeping walk order among entries of equal depth.
+func deepestFirst(items []walkItem) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return pathDepth(items[order[a]].path) > pathDepth(items[order[b]].path)
+	})
+	return order
+}
+
+// pathDepth returns the number of path elements in the absolute form of path.
+func pathDepth(path string) int {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// relocate rewrites the paths of results located below oldDir to newDir.
+func relocate(results []Result, oldDir, newDir string) {
+	prefix := oldDir + string(filepath.Separator)
+	for i := range results {
+		if strings.HasPrefix(results[i].Path, prefix) {
+			results[i].Path = filepath.Join(newDir, results[i].Path[len(prefix):])
+		}
+	}
+}
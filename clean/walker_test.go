@@ -0,0 +1,130 @@
+// walker_test.go
+// ---------------
+// Unit tests for directory traversal.
+// Tests cover recursive renaming of directories (children before parents)
+// and roots given with a trailing slash.
+
+package clean
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWalkRecursiveDirectories tests that dirty directory names and their
+// contents are all renamed, with results reporting final paths.
+func TestWalkRecursiveDirectories(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "My Dir", "Sub Dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, tmpDir, filepath.Join("My Dir", "A File.txt"), filepath.Join("My Dir", "Sub Dir", "B File.txt"))
+
+	root := filepath.Join(tmpDir, "My Dir")
+	results := Walk([]string{root}, Options{Case: "lower", Execute: true, Recursive: true})
+
+	want := map[string]string{
+		"My Dir":     filepath.Join(tmpDir, "my_dir"),
+		"A File.txt": filepath.Join(tmpDir, "my_dir", "a_file.txt"),
+		"Sub Dir":    filepath.Join(tmpDir, "my_dir", "sub_dir"),
+		"B File.txt": filepath.Join(tmpDir, "my_dir", "sub_dir", "b_file.txt"),
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Walk() returned %d results, want %d", len(results), len(want))
+	}
+	// Results stay in walk order: parents before children
+	if results[0].OldName != "My Dir" {
+		t.Errorf("Walk() first result = %q, want %q", results[0].OldName, "My Dir")
+	}
+	for _, r := range results {
+		if r.HasError() || !r.Renamed {
+			t.Errorf("Walk() %q: Renamed = %v, Error = %q", r.OldName, r.Renamed, r.Error)
+			continue
+		}
+		if r.Path != want[r.OldName] {
+			t.Errorf("Walk() %q Path = %q, want %q", r.OldName, r.Path, want[r.OldName])
+		}
+		if _, err := os.Stat(r.Path); err != nil {
+			t.Errorf("Walk() %q not found at final path: %v", r.OldName, err)
+		}
+	}
+}
+
+// TestWalkTrailingSlash tests that a root given with a trailing slash is
+// renamed like the same root without it, in preview and execution.
+func TestWalkTrailingSlash(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "Bad Dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	touch(t, tmpDir, filepath.Join("Bad Dir", "A File.txt"))
+	root := filepath.Join(tmpDir, "Bad Dir") + string(filepath.Separator)
+
+	preview := Walk([]string{root}, Options{Recursive: true})
+	results := Walk([]string{root}, Options{Recursive: true, Execute: true})
+	if len(results) != 2 || len(preview) != 2 {
+		t.Fatalf("Walk() returned %d results (preview %d), want 2", len(results), len(preview))
+	}
+	for i, r := range results {
+		if r.HasError() || !r.Renamed {
+			t.Errorf("Walk() %q: Renamed = %v, Error = %q", r.OldName, r.Renamed, r.Error)
+		}
+		if p := preview[i]; p.OldName != r.OldName || p.NewName != r.NewName {
+			t.Errorf("preview %q -> %q, execution %q -> %q", p.OldName, p.NewName, r.OldName, r.NewName)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "Bad_Dir", "A_File.txt")); err != nil {
+		t.Errorf("Walk() did not rename the tree: %v", err)
+	}
+}
+
+// TestWalkDotRoot tests that the "." walk root is never renamed.
+func TestWalkDotRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "A File.txt")
+	t.Chdir(tmpDir)
+
+	for _, r := range Walk([]string{"."}, Options{Recursive: true}) {
+		if r.HasError() {
+			t.Errorf("Walk() %q error = %q", r.Path, r.Error)
+		}
+		if r.OldName == "." && r.NewName != "." {
+			t.Errorf("Walk() proposes renaming %q to %q", r.OldName, r.NewName)
+		}
+	}
+}
+
+// TestWalkDryRunCollisions tests that dry-run predicts the suffixes execution produces.
+func TestWalkDryRunCollisions(t *testing.T) {
+	tmpDir := t.TempDir()
+	touch(t, tmpDir, "My File.txt", "My__File.txt", "my file.TXT")
+	opts := Options{Case: "lower"}
+
+	preview := Walk([]string{tmpDir}, opts)
+	opts.Execute = true
+	executed := Walk([]string{tmpDir}, opts)
+
+	if len(preview) != len(executed) {
+		t.Fatalf("Walk() returned %d preview and %d executed results", len(preview), len(executed))
+	}
+	seen := make(map[string]bool)
+	auto := 0
+	for i := range preview {
+		if preview[i].NewName != executed[i].NewName || preview[i].AutoRenamed != executed[i].AutoRenamed {
+			t.Errorf("preview %q -> %q (auto %v), executed -> %q (auto %v)",
+				preview[i].OldName, preview[i].NewName, preview[i].AutoRenamed,
+				executed[i].NewName, executed[i].AutoRenamed)
+		}
+		if seen[preview[i].NewName] {
+			t.Errorf("preview assigns %q twice", preview[i].NewName)
+		}
+		seen[preview[i].NewName] = true
+		if preview[i].AutoRenamed {
+			auto++
+		}
+	}
+	if auto != 2 {
+		t.Errorf("preview auto-renamed %d entries, want 2", auto)
+	}
+}
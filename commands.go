@@ -0,0 +1,105 @@
+// commands.go
+// ------------
+// Implements the Cleanfy subcommands:
+//   explain — show how every pipeline step transforms the given names
+//   plan    — write a reviewable plan of all renames without touching files
+//   apply   — execute a plan written by `cleanfy plan`
+//   undo    — reverse the last run recorded in the rename journal
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/johndo100/cleanfy/clean"
+)
+
+// explainNames runs the pipeline in explain mode on each name.
+// Names do not need to exist on disk; existing directories are
+// treated as directories (no extension split).
+func explainNames(names []string, opts clean.Options) []clean.Result {
+	results := make([]clean.Result, 0, len(names))
+	for _, p := range names {
+		isDir := false
+		if info, err := os.Stat(p); err == nil {
+			isDir = info.IsDir()
+		}
+		name := filepath.Base(p)
+
+		newName, steps, err := clean.ExplainName(p, name, isDir, opts)
+		r := clean.Result{Path: p, OldName: name, NewName: newName, IsDir: isDir, Steps: steps}
+		if err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// planTargets builds a plan for targets and writes it to output.
+// With no output file the plan is printed as JSON and no preview is returned.
+func planTargets(targets []string, output string, opts clean.Options) []clean.Result {
+	plan, results := clean.NewPlan(targets, opts)
+	if output == "" {
+		emitJSON(plan, true)
+		return nil
+	}
+
+	if err := plan.WriteFile(output); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "📝 Plan with %d rename(s) written to %s\n", len(plan.Entries), output)
+	return results
+}
+
+// applyPlans executes each plan file in order.
+func applyPlans(files []string, opts clean.Options) []clean.Result {
+	var results []clean.Result
+	for _, f := range files {
+		plan, err := clean.ReadPlan(f)
+		if err != nil {
+			results = append(results, clean.Result{Path: f, Error: err.Error()})
+			continue
+		}
+		results = append(results, clean.Apply(plan, opts)...)
+	}
+	return results
+}
+
+// openJournal returns the rename journal selected by --journal / --no-journal,
+// or nil if journaling is disabled.
+func openJournal() *clean.Journal {
+	if flagNoJournal {
+		return nil
+	}
+	path, err := journalPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Journal disabled: %v\n", err)
+		return nil
+	}
+	return clean.NewJournal(path)
+}
+
+// journalPath returns the --journal file or the default journal location.
+func journalPath() (string, error) {
+	if flagJournal != "" {
+		return flagJournal, nil
+	}
+	return clean.DefaultJournalPath()
+}
+
+// undoRun reverses the last run recorded in the journal file
+// (the first argument, or the --journal / default journal).
+func undoRun(args []string) []clean.Result {
+	path, err := journalPath()
+	if len(args) > 0 {
+		path, err = args[0], nil
+	}
+	if err != nil {
+		return []clean.Result{{Error: err.Error()}}
+	}
+	return clean.Undo(path, flagRun)
+}
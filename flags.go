@@ -2,29 +2,47 @@
 // --------
 // Defines all command-line flags, usage text, and global configuration options for Cleanfy.
 // Flags control behavior such as recursion, case transformation, date prefixing,
-// JSON output, and automatic conflict resolution (--unique).
+// and JSON output.
 package main
 
 import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/johndo100/cleanfy/clean"
 )
 
 var (
 	flagDo, flagRecursive, flagQuiet, flagDotfiles, flagJSON, flagVersion bool
-	flagCase, flagDateMode, flagDateFormat                                string
+	flagExplain, flagNoJournal, flagAtomic                                bool
+	flagCase, flagDateMode, flagDateFormat, flagOutput                    string
+	flagJournal, flagRun, flagOnConflict, flagSuffix, flagFSCase          string
+	flagTarget, flagShortNames, flagNameMap, flagCompoundExt, flagExtMap  string
+	flagFixExt, flagOnDangerous                                           string
+	flagTruncHash, flagNormalizeExt                                       bool
+	flagSuffixStart, flagMaxLength, flagMaxChars, flagMaxPath             int
 )
 
-// Always enable --unique behavior
-const flagUnique = true
-
-func parseFlags() {
+// parseFlags parses args (the command line without the program name and
+// command cmd) and returns the validated options.
+func parseFlags(cmd string, args []string) clean.Options {
 	// Custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "cleanfy — smart batch file renamer\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  cleanfy [options] [targets...]\n\n")
+		fmt.Fprintf(os.Stderr, "  cleanfy [options] [targets...]\n")
+		fmt.Fprintf(os.Stderr, "  cleanfy explain [options] [names...]\n")
+		fmt.Fprintf(os.Stderr, "  cleanfy plan [-o plan.json] [options] [targets...]\n")
+		fmt.Fprintf(os.Stderr, "  cleanfy apply [options] [plan.json...]\n")
+		fmt.Fprintf(os.Stderr, "  cleanfy undo [--run=id] [journal]\n\n")
+
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  explain                    Show every pipeline step for the given names (no renaming)\n")
+		fmt.Fprintf(os.Stderr, "  plan                       Write the full list of proposed renames to a plan file\n")
+		fmt.Fprintf(os.Stderr, "  apply                      Execute a plan, refusing entries changed since planning\n")
+		fmt.Fprintf(os.Stderr, "  undo                       Reverse the last run recorded in the rename journal\n\n")
 
 		fmt.Fprintf(os.Stderr, "Core:\n")
 		fmt.Fprintf(os.Stderr, "  -x, --execute              Perform actual renaming (default: preview only)\n")
@@ -32,12 +50,40 @@ func parseFlags() {
 		fmt.Fprintf(os.Stderr, "  -q, --quiet                Suppress normal output\n")
 		fmt.Fprintf(os.Stderr, "  -j, --json                 Show output in JSON format\n")
 		fmt.Fprintf(os.Stderr, "  -v, --version              Show program version and exit\n")
-		fmt.Fprintf(os.Stderr, "  -a, --dotfiles             Include hidden files (starting with .)\n\n")
+		fmt.Fprintf(os.Stderr, "  -a, --dotfiles             Include hidden files (starting with .)\n")
+		fmt.Fprintf(os.Stderr, "  --atomic                   Roll back all renames of the run if any entry fails\n")
+		fmt.Fprintf(os.Stderr, "  --explain                  Show the result of every pipeline step per file\n")
+		fmt.Fprintf(os.Stderr, "  -o, --output=file          Plan file to write (plan command; default: stdout)\n\n")
+
+		fmt.Fprintf(os.Stderr, "Journal:\n")
+		fmt.Fprintf(os.Stderr, "  --journal=file             Rename journal (default: <config dir>/cleanfy/journal.jsonl)\n")
+		fmt.Fprintf(os.Stderr, "  --no-journal               Do not record executed renames\n")
+		fmt.Fprintf(os.Stderr, "  --run=id                   Run to reverse (undo command; default: last run)\n\n")
 
 		fmt.Fprintf(os.Stderr, "Rename modifiers:\n")
+		fmt.Fprintf(os.Stderr, "  --target=value             Make names valid on: posix|windows|macos|fat32|exfat|iso9660|s3|portable\n")
 		fmt.Fprintf(os.Stderr, "  --case=value               Case transform: none|lower|upper|title\n")
 		fmt.Fprintf(os.Stderr, "  --date=value               Add date prefix: mtime|now\n")
-		fmt.Fprintf(os.Stderr, "  --date-format=value        Go time layout, e.g. 20060102 (with --date)\n\n")
+		fmt.Fprintf(os.Stderr, "  --date-format=value        Go time layout, e.g. 20060102 (with --date)\n")
+		fmt.Fprintf(os.Stderr, "  --max-length=n             Name length limit in bytes (default: 255 or the target's; -1: no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --max-chars=n              Name length limit in characters (default: none)\n")
+		fmt.Fprintf(os.Stderr, "  --trunc-hash               End shortened names in a short hash of the full name\n")
+		fmt.Fprintf(os.Stderr, "  --max-path=n               Resulting path length limit in bytes (default: the target's; -1: no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --compound-ext=list        More multi-part extensions kept whole, e.g. pkg.tar.zst,tar.br\n")
+		fmt.Fprintf(os.Stderr, "  --normalize-ext            Canonical lower-case extensions: .JPEG -> .jpg, .tiff -> .tif\n")
+		fmt.Fprintf(os.Stderr, "  --ext-map=list             Extension mappings, e.g. tif=tiff,jpeg=jpg (implies --normalize-ext)\n")
+		fmt.Fprintf(os.Stderr, "  --fix-ext[=replace]        Add missing extensions from the file content; flag (or replace) wrong ones\n")
+		fmt.Fprintf(os.Stderr, "  --short-names=value        8.3 names like LONGFI~1.TXT: dos|iso9660\n")
+		fmt.Fprintf(os.Stderr, "  --name-map=file            Write a CSV of original and final paths of all renames\n\n")
+
+		fmt.Fprintf(os.Stderr, "Conflicts:\n")
+		fmt.Fprintf(os.Stderr, "  --on-conflict=value        When a name is taken: suffix|skip|error|overwrite|hash|ask (default: suffix)\n")
+		fmt.Fprintf(os.Stderr, "  --suffix=format            Suffix layout with one %%d, e.g. _%%d, \" (%%d)\", -%%03d (default: _%%d)\n")
+		fmt.Fprintf(os.Stderr, "  --suffix-start=n           First suffix number (default: 2)\n")
+		fmt.Fprintf(os.Stderr, "  --fs-case=value            Name comparison: auto|sensitive|insensitive (default: auto)\n\n")
+
+		fmt.Fprintf(os.Stderr, "Security:\n")
+		fmt.Fprintf(os.Stderr, "  --on-dangerous=value       Disguised executables (invoice.pdf.exe): warn|rename|skip|error (default: warn)\n\n")
 
 		fmt.Fprintf(os.Stderr, "Notes:\n")
 		fmt.Fprintf(os.Stderr, "  • All value flags must use the = form (e.g. --date=now or -c=lower)\n")
@@ -60,6 +106,15 @@ func parseFlags() {
 	// File handling
 	flag.BoolVar(&flagDotfiles, "a", false, "Include hidden files (starting with .)")
 	flag.BoolVar(&flagDotfiles, "dotfiles", false, "Alias for -a")
+	flag.BoolVar(&flagAtomic, "atomic", false, "Roll back all renames of the run if any entry fails")
+	flag.BoolVar(&flagExplain, "explain", false, "Show the result of every pipeline step per file")
+	flag.StringVar(&flagOutput, "o", "", "Plan file to write (plan command)")
+	flag.StringVar(&flagOutput, "output", "", "Alias for -o")
+
+	// Journal
+	flag.StringVar(&flagJournal, "journal", "", "Rename journal file")
+	flag.BoolVar(&flagNoJournal, "no-journal", false, "Do not record executed renames")
+	flag.StringVar(&flagRun, "run", "", "Run to reverse (undo command)")
 
 	// Options
 	flag.StringVar(&flagCase, "c", "", "Case transform: none|lower|upper|title")
@@ -68,9 +123,27 @@ func parseFlags() {
 	flag.StringVar(&flagDateMode, "date", "", "Alias for -d")
 	flag.StringVar(&flagDateFormat, "f", "2006-01-02", "Date format (default: 2006-01-02)")
 	flag.StringVar(&flagDateFormat, "date-format", "2006-01-02", "Alias for -f")
+	flag.StringVar(&flagTarget, "target", "", "Destination filesystem: posix|windows|macos|fat32|exfat|iso9660|s3|portable")
+	flag.IntVar(&flagMaxLength, "max-length", 0, "Name length limit in bytes (default: 255 or the target's; -1: no limit)")
+	flag.IntVar(&flagMaxChars, "max-chars", 0, "Name length limit in characters")
+	flag.BoolVar(&flagTruncHash, "trunc-hash", false, "End shortened names in a short hash of the full name")
+	flag.StringVar(&flagCompoundExt, "compound-ext", "", "More multi-part extensions kept whole (comma-separated)")
+	flag.BoolVar(&flagNormalizeExt, "normalize-ext", false, "Canonical lower-case extensions")
+	flag.StringVar(&flagExtMap, "ext-map", "", "Extension mappings from=to (comma-separated, implies --normalize-ext)")
+	flag.Var(optionalValue{&flagFixExt, clean.FixExtAdd}, "fix-ext", "Add missing extensions from the file content: add|replace")
+	flag.StringVar(&flagShortNames, "short-names", "", "8.3 names like LONGFI~1.TXT: dos|iso9660")
+	flag.StringVar(&flagNameMap, "name-map", "", "Write a CSV of original and final paths of all renames")
+	flag.IntVar(&flagMaxPath, "max-path", 0, "Resulting path length limit in bytes")
+
+	// Conflicts
+	flag.StringVar(&flagOnConflict, "on-conflict", "", "When a name is taken: suffix|skip|error|overwrite|hash|ask")
+	flag.StringVar(&flagSuffix, "suffix", "", "Collision suffix layout with one %d")
+	flag.StringVar(&flagOnDangerous, "on-dangerous", "", "Disguised executables: warn|rename|skip|error")
+	flag.IntVar(&flagSuffixStart, "suffix-start", 0, "First collision suffix number")
+	flag.StringVar(&flagFSCase, "fs-case", "", "Name comparison: auto|sensitive|insensitive")
 
 	// Parse flags
-	flag.Parse()
+	flag.CommandLine.Parse(args)
 
 	// Handle version early
 	if flagVersion {
@@ -79,22 +152,14 @@ func parseFlags() {
 	}
 
 	// Validate target position — all flags must come before targets
-	for i, arg := range os.Args[1:] {
-		if len(arg) > 0 && arg[0] != '-' {
-			// found first target
-			for _, rest := range os.Args[i+2:] {
-				if len(rest) > 0 && rest[0] == '-' {
-					fmt.Fprintf(os.Stderr, "❌ Error: flags must appear before targets (got '%s' after '%s')\n\n", rest, arg)
-					flag.Usage()
-					os.Exit(2)
-				}
-			}
-			break
-		}
+	if misplaced, target := misplacedFlag(args, flag.Args()); misplaced != "" {
+		fmt.Fprintf(os.Stderr, "❌ Error: flags must appear before targets (got '%s' after '%s')\n\n", misplaced, target)
+		flag.Usage()
+		os.Exit(2)
 	}
 
-	// Ensure at least one target is specified
-	if flag.NArg() == 0 {
+	// Ensure at least one target is specified (undo defaults to the journal)
+	if flag.NArg() == 0 && cmd != cmdUndo {
 		fmt.Fprintln(os.Stderr, "❌ Error: no target specified.")
 		fmt.Fprintln(os.Stderr, "Hint: use '.' to scan the current directory.")
 		fmt.Fprintln(os.Stderr)
@@ -102,29 +167,115 @@ func parseFlags() {
 		os.Exit(2)
 	}
 
-	// Validate --case (only if provided)
-	if flagCase != "" {
-		switch flagCase {
-		case "none", "lower", "upper", "title":
-			// valid
-		default:
-			fmt.Fprintln(os.Stderr, "❌ Invalid --case value. Use one of: none | lower | upper | title")
-			fmt.Fprintln(os.Stderr)
-			flag.Usage()
-			os.Exit(2)
-		}
+	opts := clean.Options{
+		Case:         flagCase,
+		DateMode:     flagDateMode,
+		DateFormat:   flagDateFormat,
+		Execute:      flagDo,
+		Dotfiles:     flagDotfiles,
+		Recursive:    flagRecursive,
+		Explain:      flagExplain,
+		Atomic:       flagAtomic,
+		OnConflict:   flagOnConflict,
+		SuffixFormat: flagSuffix,
+		SuffixStart:  flagSuffixStart,
+		FSCase:       flagFSCase,
+		MaxLength:    flagMaxLength,
+		MaxChars:     flagMaxChars,
+		TruncHash:    flagTruncHash,
+		MaxPath:      flagMaxPath,
+		Target:       flagTarget,
+		ShortNames:   flagShortNames,
+		CompoundExts: compoundExts(flagCompoundExt),
+		NormalizeExt: flagNormalizeExt || flagExtMap != "",
+		ExtMap:       extMap(flagExtMap),
+		FixExt:       flagFixExt,
+		OnDangerous:  flagOnDangerous,
+	}
+	if opts.OnConflict == clean.ConflictAsk {
+		opts.Ask = askConflict
 	}
 
-	// Validate --date (only if provided)
-	if flagDateMode != "" {
-		switch flagDateMode {
-		case "mtime", "now":
-			// valid
-		default:
-			fmt.Fprintln(os.Stderr, "❌ Invalid --date value. Use one of: mtime | now")
-			fmt.Fprintln(os.Stderr)
-			flag.Usage()
-			os.Exit(2)
+	// Validate option values (--case, --date, --on-conflict, --fs-case, --target, --ext-map, --fix-ext, --on-dangerous)
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	return opts
+}
+
+// misplacedFlag returns the first flag given after a target, and that
+// target. targets are the arguments left by flag parsing (flag.Args() of
+// args), which stops at the first target, so any later argument starting
+// with '-' is a misplaced flag. Flag values (-o plan.json) are not
+// targets. After "--" all arguments are targets.
+func misplacedFlag(args, targets []string) (string, string) {
+	if len(targets) == 0 || len(args) > len(targets) && args[len(args)-len(targets)-1] == "--" {
+		return "", ""
+	}
+	for _, rest := range targets[1:] {
+		if len(rest) > 0 && rest[0] == '-' {
+			return rest, targets[0]
 		}
 	}
+	return "", ""
+}
+
+// compoundExts returns the default multi-part extensions plus those listed
+// in --compound-ext (comma-separated), or nil when the flag is not set.
+func compoundExts(list string) []string {
+	if list == "" {
+		return nil
+	}
+	exts := clean.DefaultCompoundExts()
+	for _, ext := range strings.Split(list, ",") {
+		exts = append(exts, strings.TrimPrefix(strings.TrimSpace(ext), "."))
+	}
+	return exts
+}
+
+// extMap returns the extension mappings listed in --ext-map as
+// comma-separated from=to pairs, or nil when the flag is not set.
+// Pairs without '=' map to an empty extension, which Validate rejects.
+func extMap(list string) map[string]string {
+	if list == "" {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		from, to, _ := strings.Cut(pair, "=")
+		m[strings.TrimPrefix(strings.TrimSpace(from), ".")] = strings.TrimPrefix(strings.TrimSpace(to), ".")
+	}
+	return m
 }
+
+// optionalValue is a string flag whose value may be left out: the bare
+// flag (--fix-ext) sets def, --flag=false clears it.
+type optionalValue struct {
+	p   *string
+	def string
+}
+
+func (v optionalValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return *v.p
+}
+
+func (v optionalValue) Set(s string) error {
+	switch s {
+	case "true":
+		*v.p = v.def
+	case "false":
+		*v.p = ""
+	default:
+		*v.p = s
+	}
+	return nil
+}
+
+// IsBoolFlag lets the flag package accept the flag without a value.
+func (v optionalValue) IsBoolFlag() bool { return true }
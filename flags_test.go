@@ -0,0 +1,46 @@
+// flags_test.go
+// --------------
+// Unit tests for command-line flag handling.
+// Tests cover the check that all flags come before the targets.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+// TestMisplacedFlag tests that flags after targets are found and flag values are not taken as targets.
+func TestMisplacedFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantFlag   string
+		wantTarget string
+	}{
+		{"plan-output", []string{"-o", "plan.json", "--case=lower", "./shared"}, "", ""},
+		{"output-equals", []string{"--output=plan.json", "--case=lower", "./shared", "./other"}, "", ""},
+		{"after-target", []string{"--case=lower", "./shared", "-x"}, "-x", "./shared"},
+		{"after-value", []string{"-o", "plan.json", "./shared", "--case=lower"}, "--case=lower", "./shared"},
+		{"dash-dash", []string{"--case=lower", "--", "./shared", "-odd-name"}, "", ""},
+		{"no-targets", []string{"--case=lower"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("cleanfy", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.String("o", "", "")
+		fs.String("output", "", "")
+		fs.String("case", "", "")
+		fs.Bool("x", false, "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatalf("%s: Parse() error = %v", tt.name, err)
+		}
+
+		gotFlag, gotTarget := misplacedFlag(tt.args, fs.Args())
+		if gotFlag != tt.wantFlag || gotTarget != tt.wantTarget {
+			t.Errorf("%s: misplacedFlag() = %q, %q, want %q, %q", tt.name, gotFlag, gotTarget, tt.wantFlag, tt.wantTarget)
+		}
+	}
+}
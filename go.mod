@@ -4,4 +4,7 @@ go 1.24.0
 
 toolchain go1.24.10
 
-require golang.org/x/text v0.30.0
+require (
+	golang.org/x/sys v0.41.0
+	golang.org/x/text v0.30.0
+)
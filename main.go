@@ -1,27 +1,95 @@
 // Package cleanfy
 // ----------------
 // Main entry point of the Cleanfy CLI tool.
-// Handles version display, flag parsing, directory walking, and output control.
+// Handles version display, command dispatch, flag parsing, directory walking, and output control.
 
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"runtime"
+
+	"github.com/johndo100/cleanfy/clean"
 )
 
 // version is injected at build time via -ldflags
 // Default value if not set during build
 
 func main() {
-	parseFlags()
+	cmd, args := splitCommand(os.Args[1:])
+	opts := parseFlags(cmd, args)
 
 	if flagVersion {
 		fmt.Printf("cleanfy %s (%s/%s)\n", getVersion(), runtime.GOOS, runtime.GOARCH)
 		return
 	}
 
-	results := walkAndRename()
+	var results []clean.Result
+	switch cmd {
+	case cmdExplain:
+		opts.Explain = true
+		results = explainNames(flag.Args(), opts)
+	case cmdPlan:
+		results = planTargets(flag.Args(), flagOutput, opts)
+		if results == nil {
+			return
+		}
+	case cmdApply:
+		opts.Journal = openJournal()
+		results = applyPlans(flag.Args(), opts)
+	case cmdUndo:
+		results = undoRun(flag.Args())
+	default:
+		// Warn if recursive mode is enabled
+		if opts.Recursive {
+			fmt.Println("⚠️  Recursive mode enabled — Cleanfy will process all subdirectories.")
+			fmt.Println("    Use Ctrl+C to stop if this was not intended.")
+		}
+
+		if opts.Execute {
+			opts.Journal = openJournal()
+		}
+		var names *nameMap
+		if flagNameMap != "" {
+			names = openNameMap(flagNameMap)
+			opts.MapName = names.add
+		}
+		results = clean.Walk(flag.Args(), opts)
+		if names != nil {
+			names.close()
+		}
+	}
+
+	if opts.Journal != nil {
+		opts.Journal.Close()
+	}
 
 	emitResults(results)
+
+	if opts.Atomic && clean.Failed(results) {
+		fmt.Fprintln(os.Stderr, "❌ Atomic run failed — completed renames were rolled back.")
+		os.Exit(1)
+	}
+}
+
+// Commands accepted as the first argument.
+const (
+	cmdExplain = "explain"
+	cmdPlan    = "plan"
+	cmdApply   = "apply"
+	cmdUndo    = "undo"
+)
+
+// splitCommand separates a leading command from the remaining arguments.
+// Without a command, all arguments are returned unchanged.
+func splitCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case cmdExplain, cmdPlan, cmdApply, cmdUndo:
+			return args[0], args[1:]
+		}
+	}
+	return "", args
 }
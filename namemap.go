@@ -0,0 +1,47 @@
+// namemap.go
+// -----------
+// Writes the --name-map file: a CSV of the original and final path of
+// every rename, e.g. to find files again after --short-names.
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+)
+
+// nameMap is an open --name-map file.
+type nameMap struct {
+	f *os.File
+	w *csv.Writer
+}
+
+// openNameMap creates the name map file at path and writes its header.
+func openNameMap(path string) *nameMap {
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+		os.Exit(1)
+	}
+	m := &nameMap{f: f, w: csv.NewWriter(f)}
+	m.w.Write([]string{"original", "final"})
+	return m
+}
+
+// add records one rename (used as clean.Options.MapName).
+func (m *nameMap) add(original, final string) {
+	m.w.Write([]string{original, final})
+}
+
+// close flushes and closes the file, reporting any write error.
+func (m *nameMap) close() {
+	m.w.Flush()
+	err := m.w.Error()
+	if cerr := m.f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Name map incomplete: %v\n", err)
+	}
+}
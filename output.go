@@ -2,7 +2,9 @@
 // ----------
 // Handles all output formatting for Cleanfy.
 // Supports JSON and plain-text output, with options for quiet, pretty, and error-only modes.
-// Automatically highlights auto-renamed files (via --unique).
+// Highlights auto-renamed, skipped and overwriting entries (via --on-conflict),
+// names shortened to fit --max-path, extensions not matching the content
+// (--fix-ext) and disguised executables (--on-dangerous).
 
 package main
 
@@ -11,11 +13,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+
+	"github.com/johndo100/cleanfy/clean"
 )
 
 // emitResults outputs a list of results in either JSON or plain-text format,
 // depending on the selected flags.
-func emitResults(results []Result) {
+func emitResults(results []clean.Result) {
 	if flagJSON {
 		emitJSON(results, true)
 		return
@@ -51,20 +55,69 @@ func emitJSON(v any, pretty bool) {
 
 // printResult prints one result entry in human-readable text form.
 // Handles renamed, auto-renamed, and error cases.
-func printResult(w *bufio.Writer, r Result) {
+// Pipeline steps (explain mode) are listed below the entry.
+func printResult(w *bufio.Writer, r clean.Result) {
+	defer printSteps(w, r.Steps)
+
 	if r.Error != "" {
 		fmt.Fprintf(w, "ERR     %s : %s\n", r.Path, r.Error)
 		return
 	}
+	if r.RolledBack {
+		fmt.Fprintf(w, "REVERT  %s -> %s   (rolled back)\n", r.OldName, r.NewName)
+		return
+	}
+	if r.Dangerous != "" {
+		printDangerous(w, r)
+		return
+	}
 	if r.NewName == "" || r.NewName == r.OldName {
+		if r.ExtMismatch {
+			fmt.Fprintf(w, "CHECK   %s   (content is %s)\n", r.OldName, r.Detected)
+			return
+		}
 		fmt.Fprintf(w, "OK      %s\n", r.OldName)
 		return
 	}
 
-	// Differentiate between normal rename and auto-rename
-	if r.AutoRenamed {
-		fmt.Fprintf(w, "RENAME* %s -> %s   (auto-resolved)\n", r.OldName, r.NewName)
-	} else {
+	// Differentiate between normal rename and conflict handling
+	switch {
+	case r.Conflict == clean.ConflictSkip:
+		fmt.Fprintf(w, "SKIP    %s -> %s   (name taken)\n", r.OldName, r.NewName)
+	case r.Conflict == clean.ConflictOverwrite:
+		fmt.Fprintf(w, "RENAME! %s -> %s   (overwrites existing)\n", r.OldName, r.NewName)
+	case r.ExtMismatch:
+		fmt.Fprintf(w, "CHECK   %s -> %s   (content is %s)\n", r.OldName, r.NewName, r.Detected)
+	case r.Shortened != "" && !r.AutoRenamed:
+		fmt.Fprintf(w, "RENAME~ %s -> %s   (shortened: %s)\n", r.OldName, r.NewName, r.Shortened)
+	case r.AutoRenamed:
+		fmt.Fprintf(w, "RENAME* %s -> %s   (auto-resolved: %s taken)\n", r.OldName, r.NewName, r.IntendedName)
+	default:
 		fmt.Fprintf(w, "RENAME  %s -> %s\n", r.OldName, r.NewName)
 	}
 }
+
+// printDangerous prints an entry whose name looks like a disguised
+// executable, with how it was handled.
+func printDangerous(w *bufio.Writer, r clean.Result) {
+	switch {
+	case r.WasSkipped:
+		fmt.Fprintf(w, "DANGER  %s   (%s; skipped)\n", r.OldName, r.Dangerous)
+	case r.NewName == r.OldName:
+		fmt.Fprintf(w, "DANGER  %s   (%s)\n", r.OldName, r.Dangerous)
+	default:
+		fmt.Fprintf(w, "DANGER  %s -> %s   (%s)\n", r.OldName, r.NewName, r.Dangerous)
+	}
+}
+
+// printSteps prints the intermediate name after each pipeline step.
+// The split step shows base and extension separately.
+func printSteps(w *bufio.Writer, steps []clean.Trace) {
+	for _, s := range steps {
+		if s.Step == clean.StepSplit {
+			fmt.Fprintf(w, "        %-9s %q + %q\n", s.Step, s.Base, s.Ext)
+			continue
+		}
+		fmt.Fprintf(w, "        %-9s %s\n", s.Step, s.Name)
+	}
+}
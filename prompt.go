@@ -0,0 +1,36 @@
+// prompt.go
+// ----------
+// Interactive conflict resolution for Cleanfy (--on-conflict=ask).
+// Questions go to stderr, so JSON output on stdout stays clean.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johndo100/cleanfy/clean"
+)
+
+// stdin is shared by all prompts of a run.
+var stdin = bufio.NewReader(os.Stdin)
+
+// askConflict asks how to resolve one conflict and returns the chosen
+// strategy. An empty answer or the end of input keeps the entry unchanged.
+func askConflict(path, name string) string {
+	fmt.Fprintf(os.Stderr, "❓ %s: %q is taken — [s]uffix, [h]ash, [o]verwrite, [k]eep, [e]rror? ", path, name)
+	line, _ := stdin.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "s", "suffix":
+		return clean.ConflictSuffix
+	case "h", "hash":
+		return clean.ConflictHash
+	case "o", "overwrite":
+		return clean.ConflictOverwrite
+	case "e", "error":
+		return clean.ConflictError
+	}
+	return clean.ConflictSkip
+}
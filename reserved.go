@@ -1,17 +0,0 @@
-// reserved.go
-// ------------
-// Checks for Windows reserved device names to avoid invalid filenames.
-
-package main
-
-import "strings"
-
-func isWindowsReserved(name string) bool {
-	switch strings.ToUpper(name) {
-	case "CON", "PRN", "AUX", "NUL",
-		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
-		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
-		return true
-	}
-	return false
-}